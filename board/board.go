@@ -1,9 +1,9 @@
 package board
 
-type cellState uint8
+type CellState uint8
 
 const (
-	Empty cellState = iota
+	Empty CellState = iota
 	Filled
 	Crossed
 )
@@ -15,14 +15,14 @@ func New(width, height int) *Board {
 	return &Board{
 		width:  width,
 		height: height,
-		cells:  make([]cellState, width*height),
+		cells:  make([]CellState, width*height),
 	}
 }
 
 type Board struct {
 	width  int
 	height int
-	cells  []cellState
+	cells  []CellState
 }
 
 func (t *Board) index(x, y int) int {
@@ -33,7 +33,7 @@ func (t *Board) Size() (width, height int) {
 	return t.width, t.height
 }
 
-func (t *Board) Get(x, y int) cellState {
+func (t *Board) Get(x, y int) CellState {
 	i := t.index(x, y)
 	if i < 0 || i >= len(t.cells) {
 		return Empty
@@ -41,7 +41,7 @@ func (t *Board) Get(x, y int) cellState {
 	return t.cells[i]
 }
 
-func (t *Board) Set(x, y int, state cellState) error {
+func (t *Board) Set(x, y int, state CellState) error {
 	i := t.index(x, y)
 	if i < 0 || i >= len(t.cells) {
 		return ErrCellOutOfBounds{}
@@ -50,7 +50,7 @@ func (t *Board) Set(x, y int, state cellState) error {
 	return nil
 }
 
-func (t *Board) SetRow(y int, states ...cellState) error {
+func (t *Board) SetRow(y int, states ...CellState) error {
 	if y < 0 || y >= t.height || len(states) != t.width {
 		return ErrCellOutOfBounds{}
 	}
@@ -62,7 +62,7 @@ func (t *Board) SetRow(y int, states ...cellState) error {
 	return nil
 }
 
-func (t *Board) SetColumn(x int, states ...cellState) error {
+func (t *Board) SetColumn(x int, states ...CellState) error {
 	if x < 0 || x >= t.width || len(states) != t.height {
 		return ErrCellOutOfBounds{}
 	}
@@ -76,7 +76,37 @@ func (t *Board) SetColumn(x int, states ...cellState) error {
 
 func (t *Board) Clone() *Board {
 	clone := *t
-	clone.cells = make([]cellState, len(t.cells))
+	clone.cells = make([]CellState, len(t.cells))
 	copy(clone.cells, t.cells)
 	return &clone
 }
+
+func (t *Board) Row(y int) []CellState {
+	if y < 0 || y >= t.height {
+		return nil
+	}
+	row := make([]CellState, t.width)
+	copy(row, t.cells[y*t.width:(y+1)*t.width])
+	return row
+}
+
+func (t *Board) Column(x int) []CellState {
+	if x < 0 || x >= t.width {
+		return nil
+	}
+	column := make([]CellState, t.height)
+	for y := range column {
+		column[y] = t.cells[t.index(x, y)]
+	}
+	return column
+}
+
+func (t *Board) Count(state CellState) int {
+	count := 0
+	for _, cell := range t.cells {
+		if cell == state {
+			count++
+		}
+	}
+	return count
+}
@@ -6,3 +6,11 @@ type ErrCellOutOfBounds struct {
 func (e ErrCellOutOfBounds) Error() string {
 	return "cell out of bounds"
 }
+
+type ErrInvalidJSON struct {
+	reason string
+}
+
+func (e ErrInvalidJSON) Error() string {
+	return "invalid board JSON: " + e.reason
+}
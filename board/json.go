@@ -0,0 +1,73 @@
+package board
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// cellChars are the characters of the string grid, indexed by CellState.
+const cellChars = ".#X"
+
+// MarshalJSON encodes the board as an array of rows, one string per row with
+// "." for empty, "#" for filled and "X" for crossed cells:
+//
+//	["#.X", "..#"]
+func (t *Board) MarshalJSON() ([]byte, error) {
+	rows := make([]string, t.height)
+	for y := range rows {
+		var row strings.Builder
+		for _, cell := range t.Row(y) {
+			row.WriteByte(cellChars[cell])
+		}
+		rows[y] = row.String()
+	}
+	return json.Marshal(rows)
+}
+
+// UnmarshalJSON decodes the string grid written by MarshalJSON or a matrix
+// of rows with the numeric cell states, 0 for empty, 1 for filled and 2 for
+// crossed cells:
+//
+//	[[1, 0, 2], [0, 0, 1]]
+func (t *Board) UnmarshalJSON(data []byte) error {
+	var rows []string
+	if err := json.Unmarshal(data, &rows); err == nil {
+		matrix := make([][]CellState, len(rows))
+		for y, row := range rows {
+			for _, r := range row {
+				i := strings.IndexRune(cellChars, r)
+				if i < 0 {
+					return ErrInvalidJSON{reason: fmt.Sprintf("unknown cell %q", r)}
+				}
+				matrix[y] = append(matrix[y], CellState(i))
+			}
+		}
+		return t.fromMatrix(matrix)
+	}
+
+	var matrix [][]CellState
+	if err := json.Unmarshal(data, &matrix); err != nil {
+		return ErrInvalidJSON{reason: "expected an array of strings or an array of arrays of cell states"}
+	}
+	return t.fromMatrix(matrix)
+}
+
+func (t *Board) fromMatrix(matrix [][]CellState) error {
+	if len(matrix) == 0 || len(matrix[0]) == 0 {
+		return ErrInvalidJSON{reason: "empty board"}
+	}
+	b := New(len(matrix[0]), len(matrix))
+	for y, row := range matrix {
+		for _, cell := range row {
+			if cell > Crossed {
+				return ErrInvalidJSON{reason: fmt.Sprintf("unknown cell state %d", cell)}
+			}
+		}
+		if err := b.SetRow(y, row...); err != nil {
+			return ErrInvalidJSON{reason: fmt.Sprintf("row %d has %d cells, expected %d", y, len(row), b.width)}
+		}
+	}
+	*t = *b
+	return nil
+}
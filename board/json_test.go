@@ -0,0 +1,30 @@
+package board
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestJSON(t *testing.T) {
+	b := New(3, 2)
+	b.SetRow(0, Filled, Empty, Crossed)
+	b.SetRow(1, Empty, Empty, Filled)
+
+	data, err := json.Marshal(b)
+	require.NoError(t, err)
+	require.JSONEq(t, `["#.X", "..#"]`, string(data))
+
+	decoded := &Board{}
+	require.NoError(t, json.Unmarshal(data, decoded))
+	require.Equal(t, b, decoded)
+
+	decoded = &Board{}
+	require.NoError(t, json.Unmarshal([]byte(`[[1, 0, 2], [0, 0, 1]]`), decoded))
+	require.Equal(t, b, decoded)
+
+	for _, invalid := range []string{`["#.", "#"]`, `["#?"]`, `[[3]]`, `[]`, `{}`} {
+		require.ErrorAs(t, json.Unmarshal([]byte(invalid), &Board{}), &ErrInvalidJSON{}, invalid)
+	}
+}
@@ -0,0 +1,118 @@
+// Command bench solves every puzzle of the given files and prints how long
+// each one took. Files ending in .xml are read as webpbn puzzle sets, files
+// ending in .non in the .non format and anything else in the text format.
+//
+//	go run ./cmd/bench -solver probe -timeout 10s puzzles/*.xml
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"maps"
+	"nonogram/board"
+	_ "nonogram/cnf"
+	"nonogram/encoding"
+	"nonogram/solver"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+func main() {
+	name := flag.String("solver", solver.DefaultSolver, "solver strategy, one of "+strings.Join(solver.Names(), ", "))
+	timeout := flag.Duration("timeout", time.Minute, "time limit for each puzzle")
+	flag.Parse()
+
+	s, err := solver.Lookup(*name)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "puzzle\tsize\tstatus\ttook\tboards")
+	total := time.Duration(0)
+	counts := map[string]int{}
+	for _, path := range flag.Args() {
+		puzzles, err := read(path)
+		if err != nil {
+			log.Fatalf("%s: %v", path, err)
+		}
+		for i, p := range puzzles {
+			label := fmt.Sprintf("%s#%d", filepath.Base(path), i+1)
+			if p.Title != "" {
+				label += " " + p.Title
+			}
+			if p.Colored() {
+				counts["skipped"]++
+				fmt.Fprintf(w, "%s\t\tskipped (colored)\t\t\n", label)
+				continue
+			}
+
+			ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+			start := time.Now()
+			result, err := s.Solve(ctx, p.Board, p.Hints)
+			took := time.Since(start)
+			cancel()
+			total += took
+
+			status := string(result.Status)
+			switch {
+			case err != nil:
+				status = err.Error()
+			case p.Solution != nil && result.Status == solver.Solved && !same(p.Solution, result.Board):
+				status = "wrong"
+			}
+			counts[status]++
+			fmt.Fprintf(w, "%s\t%dx%d\t%s\t%s\t%d\n", label, len(p.Hints.Vertical), len(p.Hints.Horizontal), status, took, result.Stats.Count)
+		}
+	}
+	w.Flush()
+	fmt.Printf("\ntotal %s", total)
+	for _, status := range slices.Sorted(maps.Keys(counts)) {
+		fmt.Printf(", %s %d", status, counts[status])
+	}
+	fmt.Println()
+}
+
+func read(path string) ([]*encoding.Puzzle, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".xml":
+		return encoding.DecodeXML(file)
+	case ".non":
+		p, err := encoding.DecodeNON(file)
+		if err != nil {
+			return nil, err
+		}
+		return []*encoding.Puzzle{p}, nil
+	default:
+		b, h, err := encoding.Decode(file)
+		if err != nil {
+			return nil, err
+		}
+		return []*encoding.Puzzle{{Board: b, Hints: h}}, nil
+	}
+}
+
+// same reports whether both boards have the same filled cells.
+func same(a, b *board.Board) bool {
+	width, height := a.Size()
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			if (a.Get(x, y) == board.Filled) != (b.Get(x, y) == board.Filled) {
+				return false
+			}
+		}
+	}
+	return true
+}
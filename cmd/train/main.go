@@ -0,0 +1,90 @@
+// Command train makes digit templates for the screen decoder out of
+// screenshots of puzzles with known clues. Arguments come in pairs of a PNG
+// screenshot and the puzzle in the text format. Digits are merged across all
+// the screenshots of a grid size and written as {size}_{digit}.png, replacing
+// the templates of the digits it saw and keeping the others.
+//
+//	go run ./cmd/train -out screen/samples 15x15.png 15x15.txt 20x20.png 20x20.txt
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"maps"
+	"nonogram/encoding"
+	"nonogram/screen"
+	"os"
+	"slices"
+	"strings"
+	"text/tabwriter"
+)
+
+func main() {
+	out := flag.String("out", "", "directory the templates are written to, required")
+	threshold := flag.Float64("threshold", screen.DefaultThreshold, "brightness, from 0 to 1, above which a pixel is background")
+	flag.Parse()
+
+	if *out == "" {
+		log.Fatal("-out is required")
+	}
+	args := flag.Args()
+	if len(args) == 0 || len(args)%2 != 0 {
+		log.Fatal("expected pairs of a screenshot and its puzzle text")
+	}
+
+	trainer := &screen.Trainer{Decoder: &screen.Decoder{Threshold: *threshold}}
+	for i := 0; i < len(args); i += 2 {
+		screenshot, puzzle := args[i], args[i+1]
+		skipped, err := add(trainer, screenshot, puzzle)
+		if err != nil {
+			log.Fatalf("%s: %v", screenshot, err)
+		}
+		if skipped > 0 {
+			fmt.Printf("%s: skipped %d clue cell(s) with touching or missing digits\n", screenshot, skipped)
+		}
+	}
+
+	if err := os.MkdirAll(*out, 0o755); err != nil {
+		log.Fatal(err)
+	}
+	if err := trainer.Templates().Save(*out); err != nil {
+		log.Fatal(err)
+	}
+
+	counts := trainer.Counts()
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "size\t0\t1\t2\t3\t4\t5\t6\t7\t8\t9\tmissing")
+	for _, size := range slices.Sorted(maps.Keys(counts)) {
+		fields := []string{fmt.Sprint(size)}
+		missing := []int{}
+		for digit, n := range counts[size] {
+			fields = append(fields, fmt.Sprint(n))
+			if n == 0 {
+				missing = append(missing, digit)
+			}
+		}
+		fields = append(fields, fmt.Sprint(missing))
+		fmt.Fprintln(w, strings.Join(fields, "\t"))
+	}
+	w.Flush()
+}
+
+func add(trainer *screen.Trainer, screenshot, puzzle string) (int, error) {
+	text, err := os.Open(puzzle)
+	if err != nil {
+		return 0, err
+	}
+	defer text.Close()
+	_, h, err := encoding.Decode(text)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", puzzle, err)
+	}
+
+	file, err := os.Open(screenshot)
+	if err != nil {
+		return 0, err
+	}
+	defer file.Close()
+	return trainer.Add(file, h)
+}
@@ -0,0 +1,205 @@
+package cnf
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"nonogram/board"
+	"nonogram/hint"
+	"strconv"
+	"strings"
+)
+
+// Formula is a boolean formula in conjunctive normal form. Variables are
+// numbered from 1, and a negative literal is the negation of its variable.
+// The first width*height variables are the cells of the board in row order,
+// true when the cell is filled.
+type Formula struct {
+	Vars    int
+	Clauses [][]int
+}
+
+func (t *Formula) newVar() int {
+	t.Vars++
+	return t.Vars
+}
+
+func (t *Formula) add(clause ...int) {
+	t.Clauses = append(t.Clauses, clause)
+}
+
+// Encode translates the puzzle into a formula using one variable per
+// possible start of every block. Known cells become unit clauses.
+func Encode(b *board.Board, h *hint.Hints) (*Formula, error) {
+	width, height := b.Size()
+	if len(h.Vertical) != width || len(h.Horizontal) != height {
+		_, err := h.Check(b)
+		return nil, err
+	}
+
+	f := &Formula{Vars: width * height}
+	for y := 0; y < height; y++ {
+		cells := make([]int, width)
+		for x := range cells {
+			cells[x] = cell(width, x, y)
+		}
+		encodeLine(f, cells, h.Horizontal[y])
+	}
+	for x := 0; x < width; x++ {
+		cells := make([]int, height)
+		for y := range cells {
+			cells[y] = cell(width, x, y)
+		}
+		encodeLine(f, cells, h.Vertical[x])
+	}
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			switch b.Get(x, y) {
+			case board.Filled:
+				f.add(cell(width, x, y))
+			case board.Crossed:
+				f.add(-cell(width, x, y))
+			}
+		}
+	}
+	return f, nil
+}
+
+func encodeLine(f *Formula, cells []int, clue []int) {
+	blocks := []int{}
+	for _, size := range clue {
+		if size > 0 {
+			blocks = append(blocks, size)
+		}
+	}
+	if len(blocks) == 0 {
+		for _, c := range cells {
+			f.add(-c)
+		}
+		return
+	}
+
+	n := len(cells)
+	total := len(blocks) - 1
+	for _, size := range blocks {
+		total += size
+	}
+
+	// starts[j][i] is the variable telling that block j starts at cell
+	// first[j]+i.
+	first := make([]int, len(blocks))
+	starts := make([][]int, len(blocks))
+	covering := make([][]int, n)
+	before := 0
+	for j, size := range blocks {
+		first[j] = before
+		for s := before; s <= n-(total-before); s++ {
+			v := f.newVar()
+			starts[j] = append(starts[j], v)
+			for i := s; i < s+size; i++ {
+				f.add(-v, cells[i])
+				covering[i] = append(covering[i], v)
+			}
+		}
+		f.add(starts[j]...)
+		for a := 0; a < len(starts[j]); a++ {
+			for b := a + 1; b < len(starts[j]); b++ {
+				f.add(-starts[j][a], -starts[j][b])
+			}
+		}
+		before += size + 1
+	}
+
+	for j := 0; j+1 < len(blocks); j++ {
+		for a, v := range starts[j] {
+			for b, w := range starts[j+1] {
+				if first[j+1]+b < first[j]+a+blocks[j]+1 {
+					f.add(-v, -w)
+				}
+			}
+		}
+	}
+
+	for i, c := range cells {
+		f.add(append([]int{-c}, covering[i]...)...)
+	}
+}
+
+func cell(width, x, y int) int {
+	return y*width + x + 1
+}
+
+// WriteDIMACS writes the formula in the DIMACS CNF format read by most SAT
+// solvers.
+func WriteDIMACS(w io.Writer, f *Formula) error {
+	bw := bufio.NewWriter(w)
+	fmt.Fprintf(bw, "p cnf %d %d\n", f.Vars, len(f.Clauses))
+	for _, clause := range f.Clauses {
+		for _, literal := range clause {
+			bw.WriteString(strconv.Itoa(literal))
+			bw.WriteString(" ")
+		}
+		bw.WriteString("0\n")
+	}
+	return bw.Flush()
+}
+
+// ReadModel reads the answer of a SAT solver, either in the competition
+// format ("s SATISFIABLE" and "v" lines) or in the minisat result file format
+// ("SAT" followed by the literals). model[i] is the value of variable i+1.
+func ReadModel(r io.Reader, vars int) (model []bool, satisfiable bool, err error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 64*1024*1024)
+	model = make([]bool, vars)
+	known := false
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		switch {
+		case line == "" || strings.HasPrefix(line, "c"):
+			continue
+		case line == "s SATISFIABLE" || line == "SAT":
+			known, satisfiable = true, true
+			continue
+		case line == "s UNSATISFIABLE" || line == "UNSAT":
+			return nil, false, nil
+		case strings.HasPrefix(line, "s "):
+			return nil, false, ErrUnknownResult{result: line}
+		case strings.HasPrefix(line, "v"):
+			line = strings.TrimPrefix(line, "v")
+		}
+		for _, field := range strings.Fields(line) {
+			literal, err := strconv.Atoi(field)
+			if err != nil {
+				return nil, false, err
+			}
+			if literal > 0 && literal <= vars {
+				model[literal-1] = true
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, false, err
+	}
+	if !known {
+		return nil, false, ErrUnknownResult{result: "no result"}
+	}
+	return model, satisfiable, nil
+}
+
+// Decode builds the board described by the cell variables of model.
+func Decode(model []bool, width, height int) (*board.Board, error) {
+	if len(model) < width*height {
+		return nil, ErrModelTooSmall{expected: width * height, actual: len(model)}
+	}
+	b := board.New(width, height)
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			if model[cell(width, x, y)-1] {
+				b.Set(x, y, board.Filled)
+			} else {
+				b.Set(x, y, board.Crossed)
+			}
+		}
+	}
+	return b, nil
+}
@@ -0,0 +1,174 @@
+package cnf
+
+import (
+	"bytes"
+	"context"
+	"nonogram/board"
+	"nonogram/hint"
+	"nonogram/solver"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// models returns the distinct boards of every model of f, found by plain
+// backtracking over the variables.
+func models(f *Formula, width, height int) []string {
+	seen := map[string]bool{}
+	assignment := make([]int, f.Vars+1)
+	var search func(v int)
+	search = func(v int) {
+		for _, clause := range f.Clauses {
+			satisfied, open := false, false
+			for _, literal := range clause {
+				value := assignment[abs(literal)]
+				switch {
+				case value == 0:
+					open = true
+				case (value > 0) == (literal > 0):
+					satisfied = true
+				}
+			}
+			if !satisfied && !open {
+				return
+			}
+		}
+		if v > f.Vars {
+			model := make([]bool, f.Vars)
+			for i := range model {
+				model[i] = assignment[i+1] > 0
+			}
+			b, _ := Decode(model, width, height)
+			seen[render(b)] = true
+			return
+		}
+		for _, value := range []int{1, -1} {
+			assignment[v] = value
+			search(v + 1)
+		}
+		assignment[v] = 0
+	}
+	search(1)
+	boards := []string{}
+	for b := range seen {
+		boards = append(boards, b)
+	}
+	return boards
+}
+
+func abs(v int) int {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
+
+func render(b *board.Board) string {
+	var r strings.Builder
+	width, height := b.Size()
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			if b.Get(x, y) == board.Filled {
+				r.WriteString("#")
+			} else {
+				r.WriteString(".")
+			}
+		}
+		r.WriteString("\n")
+	}
+	return r.String()
+}
+
+func TestEncode(t *testing.T) {
+	unique := hint.New([][]int{{1, 1}, {1}, {2}}, [][]int{{2}, {1}, {1, 1}})
+	f, err := Encode(board.New(3, 3), unique)
+	require.NoError(t, err)
+	require.Equal(t, []string{"##.\n..#\n#.#\n"}, models(f, 3, 3))
+
+	ambiguous := hint.New([][]int{{1}, {1}, {1}}, [][]int{{1}, {1}, {1}})
+	f, err = Encode(board.New(3, 3), ambiguous)
+	require.NoError(t, err)
+	require.Len(t, models(f, 3, 3), 6)
+
+	known := board.New(3, 3)
+	known.Set(0, 0, board.Filled)
+	known.Set(1, 1, board.Crossed)
+	f, err = Encode(known, ambiguous)
+	require.NoError(t, err)
+	require.Equal(t, []string{"#..\n..#\n.#.\n"}, models(f, 3, 3))
+
+	empty := hint.New([][]int{{0}, {3}}, [][]int{{1}, {1}, {1}})
+	f, err = Encode(board.New(2, 3), empty)
+	require.NoError(t, err)
+	require.Equal(t, []string{".#\n.#\n.#\n"}, models(f, 2, 3))
+
+	_, err = Encode(board.New(2, 2), empty)
+	require.ErrorAs(t, err, &hint.ErrInvalidBoardSize{})
+}
+
+func TestWriteDIMACS(t *testing.T) {
+	w := new(bytes.Buffer)
+	err := WriteDIMACS(w, &Formula{Vars: 3, Clauses: [][]int{{1, -2}, {3}}})
+	require.NoError(t, err)
+	require.Equal(t, "p cnf 3 2\n1 -2 0\n3 0\n", w.String())
+}
+
+func TestReadModel(t *testing.T) {
+	model, satisfiable, err := ReadModel(strings.NewReader("c kissat\ns SATISFIABLE\nv 1 -2\nv 3 0\n"), 3)
+	require.NoError(t, err)
+	require.True(t, satisfiable)
+	require.Equal(t, []bool{true, false, true}, model)
+
+	model, satisfiable, err = ReadModel(strings.NewReader("SAT\n-1 2 -3 0\n"), 3)
+	require.NoError(t, err)
+	require.True(t, satisfiable)
+	require.Equal(t, []bool{false, true, false}, model)
+
+	_, satisfiable, err = ReadModel(strings.NewReader("s UNSATISFIABLE\n"), 3)
+	require.NoError(t, err)
+	require.False(t, satisfiable)
+
+	_, _, err = ReadModel(strings.NewReader(""), 3)
+	require.ErrorAs(t, err, &ErrUnknownResult{})
+}
+
+func TestSolverFallback(t *testing.T) {
+	h := hint.New([][]int{{1, 1}, {1}, {2}}, [][]int{{2}, {1}, {1, 1}})
+
+	_, err := Solver{Command: "nonogram-missing-sat-solver"}.Solve(context.Background(), board.New(3, 3), h)
+	require.ErrorAs(t, err, &ErrNoSATSolver{})
+
+	result, err := Solver{Command: "nonogram-missing-sat-solver", Fallback: solver.Backtracking{}}.Solve(context.Background(), board.New(3, 3), h)
+	require.NoError(t, err)
+	require.Equal(t, solver.Solved, result.Status)
+	require.Equal(t, "##.\n..#\n#.#\n", render(result.Board))
+}
+
+func TestSolverCommand(t *testing.T) {
+	command := filepath.Join(t.TempDir(), "kissat")
+	script := `#!/bin/sh
+if [ -f "$0.ran" ]; then
+	echo "s UNSATISFIABLE"
+	exit 20
+fi
+touch "$0.ran"
+echo "s SATISFIABLE"
+echo "v 1 2 -3 -4 -5 6 7 -8 9 0"
+exit 10
+`
+	require.NoError(t, os.WriteFile(command, []byte(script), 0755))
+
+	h := hint.New([][]int{{1, 1}, {1}, {2}}, [][]int{{2}, {1}, {1, 1}})
+	result, err := Solver{Command: command}.Solve(context.Background(), board.New(3, 3), h)
+	require.NoError(t, err)
+	require.Equal(t, solver.Solved, result.Status)
+	require.Equal(t, uint64(2), result.Stats.Count)
+	require.Equal(t, "##.\n..#\n#.#\n", render(result.Board))
+
+	result, err = Solver{Command: command}.Solve(context.Background(), board.New(3, 3), h)
+	require.NoError(t, err)
+	require.Equal(t, solver.NoSolution, result.Status)
+}
@@ -0,0 +1,28 @@
+package cnf
+
+import "fmt"
+
+type ErrUnknownResult struct {
+	result string
+}
+
+func (e ErrUnknownResult) Error() string {
+	return fmt.Sprintf("unknown SAT solver result: %s", e.result)
+}
+
+type ErrModelTooSmall struct {
+	expected int
+	actual   int
+}
+
+func (e ErrModelTooSmall) Error() string {
+	return fmt.Sprintf("model too small: expected at least %d variables, got %d", e.expected, e.actual)
+}
+
+type ErrNoSATSolver struct {
+	commands []string
+}
+
+func (e ErrNoSATSolver) Error() string {
+	return fmt.Sprintf("no SAT solver found on PATH, looked for %v", e.commands)
+}
@@ -0,0 +1,177 @@
+package cnf
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"nonogram/board"
+	"nonogram/hint"
+	"nonogram/solver"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"time"
+)
+
+// Commands are the SAT solver binaries looked up on PATH, in order, when
+// Solver.Command is empty. minisat writes its model to a file, the others
+// print it in the competition format.
+var Commands = []string{"kissat", "cadical", "minisat"}
+
+var (
+	_ solver.Solver = Solver{}
+)
+
+// Solver solves puzzles by running a SAT solver binary on the encoded
+// formula. When no binary is available it uses Fallback, if set.
+type Solver struct {
+	Command  string
+	Fallback solver.Solver
+}
+
+func init() {
+	solver.Register("sat", Solver{Fallback: solver.Backtracking{}})
+}
+
+// Solve runs the SAT solver once to find a solution and once more, with a
+// clause excluding that solution, to tell whether it is the only one.
+func (t Solver) Solve(ctx context.Context, b *board.Board, h *hint.Hints) (solver.Result, error) {
+	result, err := t.solve(ctx, b, h)
+	if result.Took == 0 {
+		result.Took = time.Since(result.Stats.Start)
+	}
+	return result, err
+}
+
+func (t Solver) solve(ctx context.Context, b *board.Board, h *hint.Hints) (solver.Result, error) {
+	result := solver.Result{}
+	result.Stats.Start = time.Now()
+
+	_, err := h.Check(b)
+	if errors.As(err, &hint.ErrInvalid{}) {
+		result.Status = solver.NoSolution
+		return result, nil
+	}
+	if err != nil {
+		return result, err
+	}
+
+	command, err := t.command()
+	if errors.As(err, &ErrNoSATSolver{}) && t.Fallback != nil {
+		return t.Fallback.Solve(ctx, b, h)
+	}
+	if err != nil {
+		return result, err
+	}
+
+	f, err := Encode(b, h)
+	if err != nil {
+		return result, err
+	}
+	model, satisfiable, err := run(ctx, command, f)
+	result.Stats.Count++
+	if err != nil {
+		result.Status = solver.Partial
+		return result, err
+	}
+	if !satisfiable {
+		result.Status = solver.NoSolution
+		return result, nil
+	}
+	width, height := b.Size()
+	solved, err := Decode(model, width, height)
+	if err != nil {
+		return result, err
+	}
+
+	different := make([]int, width*height)
+	for i := range different {
+		different[i] = i + 1
+		if model[i] {
+			different[i] = -different[i]
+		}
+	}
+	f.add(different...)
+	_, satisfiable, err = run(ctx, command, f)
+	result.Stats.Count++
+	if err != nil {
+		result.Status = solver.Partial
+		return result, err
+	}
+
+	result.Board = solved
+	result.Status = solver.Solved
+	if satisfiable {
+		result.Status = solver.Ambiguous
+	}
+	return result, nil
+}
+
+func (t Solver) command() (string, error) {
+	commands := Commands
+	if t.Command != "" {
+		commands = []string{t.Command}
+	}
+	for _, command := range commands {
+		path, err := exec.LookPath(command)
+		if err == nil {
+			return path, nil
+		}
+	}
+	return "", ErrNoSATSolver{commands: commands}
+}
+
+func run(ctx context.Context, command string, f *Formula) ([]bool, bool, error) {
+	dir, err := os.MkdirTemp("", "nonogram-cnf-")
+	if err != nil {
+		return nil, false, err
+	}
+	defer os.RemoveAll(dir)
+
+	input := filepath.Join(dir, "puzzle.cnf")
+	file, err := os.Create(input)
+	if err != nil {
+		return nil, false, err
+	}
+	err = WriteDIMACS(file, f)
+	file.Close()
+	if err != nil {
+		return nil, false, err
+	}
+
+	output := filepath.Join(dir, "model.txt")
+	minisat := filepath.Base(command) == "minisat"
+	args := []string{input}
+	if minisat {
+		args = append(args, output)
+	}
+
+	stdout := new(bytes.Buffer)
+	cmd := exec.CommandContext(ctx, command, args...)
+	cmd.Stdout = stdout
+	err = cmd.Run()
+	if cause := context.Cause(ctx); cause != nil {
+		return nil, false, cause
+	}
+	// SAT solvers exit with 10 when the formula is satisfiable and with 20
+	// when it is not.
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) && (exitErr.ExitCode() == 10 || exitErr.ExitCode() == 20) {
+		err = nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+
+	var result io.Reader = stdout
+	if minisat {
+		file, err := os.Open(output)
+		if err != nil {
+			return nil, false, err
+		}
+		defer file.Close()
+		result = file
+	}
+	return ReadModel(result, f.Vars)
+}
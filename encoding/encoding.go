@@ -3,9 +3,11 @@ package encoding
 import (
 	"bufio"
 	"errors"
+	"fmt"
 	"io"
 	"nonogram/board"
 	"nonogram/hint"
+	"slices"
 	"strconv"
 	"strings"
 )
@@ -14,11 +16,15 @@ var (
 	errEmptyLine = errors.New("empty line encountered")
 )
 
+// gridChars are the characters of the grid section, indexed by
+// board.CellState.
+const gridChars = ".#X"
+
 func Decode(r io.Reader) (*board.Board, *hint.Hints, error) {
-	scanner := bufio.NewScanner(r)
+	scanner := newLineScanner(r)
 	width, height, err := decodeSize(scanner)
 	if err != nil {
-		return nil, nil, err
+		return nil, nil, scanner.wrap(SectionSize, err)
 	}
 	b := board.New(width, height)
 	vertical := make([][]int, width)
@@ -30,7 +36,7 @@ func Decode(r io.Reader) (*board.Board, *hint.Hints, error) {
 			continue
 		}
 		if err != nil {
-			return nil, nil, err
+			return nil, nil, scanner.wrap(SectionColumnClues, err)
 		}
 		vertical[x] = hints
 	}
@@ -41,20 +47,29 @@ func Decode(r io.Reader) (*board.Board, *hint.Hints, error) {
 			continue
 		}
 		if err != nil {
-			return nil, nil, err
+			return nil, nil, scanner.wrap(SectionRowClues, err)
 		}
 		horizontal[y] = hints
 	}
-	for {
-		x, y, v, err := decodeKnownCell(scanner)
-		if errors.Is(err, errEmptyLine) {
+	gridRow := 0
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
 			continue
 		}
-		if errors.As(err, &ErrUnexpectedEOF{}) {
-			break
+		if isGridRow(line) {
+			if err := decodeGridRow(b, gridRow, line); err != nil {
+				return nil, nil, scanner.wrap(SectionKnownCells, err)
+			}
+			gridRow++
+			continue
+		}
+		x, y, v, err := decodeKnownCell(line)
+		if err == nil && (x >= width || y >= height) {
+			err = ErrInvalidPosition{}
 		}
 		if err != nil {
-			return nil, nil, err
+			return nil, nil, scanner.wrap(SectionKnownCells, err)
 		}
 		switch v {
 		case 0:
@@ -63,10 +78,50 @@ func Decode(r io.Reader) (*board.Board, *hint.Hints, error) {
 			b.Set(x, y, board.Filled)
 		}
 	}
-	return b, hint.New(vertical, horizontal), nil
+	if err := scanner.Err(); err != nil {
+		return nil, nil, err
+	}
+	h := hint.New(vertical, horizontal)
+	if err := h.Validate(b); err != nil {
+		return nil, nil, err
+	}
+	return b, h, nil
+}
+
+// lineScanner is a bufio.Scanner that counts lines, so errors can point at
+// the line that caused them.
+type lineScanner struct {
+	*bufio.Scanner
+	line int
+	eof  bool
+}
+
+func newLineScanner(r io.Reader) *lineScanner {
+	return &lineScanner{Scanner: bufio.NewScanner(r)}
+}
+
+func (s *lineScanner) Scan() bool {
+	if s.eof {
+		return false
+	}
+	s.line++
+	if !s.Scanner.Scan() {
+		s.eof = true
+		return false
+	}
+	return true
 }
 
-func decodeSize(scanner *bufio.Scanner) (width, height int, err error) {
+// wrap adds the current line to err.
+func (s *lineScanner) wrap(section Section, err error) error {
+	text := ""
+	if !s.eof {
+		text = s.Text()
+	}
+	return ErrParse{Line: s.line, Text: text, Section: section, Err: err}
+}
+
+func decodeSize(scanner *lineScanner) (width, height int, err error) {
 	if !scanner.Scan() {
 		return 0, 0, ErrUnexpectedEOF{}
 	}
@@ -75,11 +130,11 @@ func decodeSize(scanner *bufio.Scanner) (width, height int, err error) {
 	if len(fields) != 2 {
 		return 0, 0, ErrInvalidSize{}
 	}
-	width, err = strconv.Atoi(fields[0])
+	width, err = atoi(fields[0])
 	if err != nil {
 		return 0, 0, err
 	}
-	height, err = strconv.Atoi(fields[1])
+	height, err = atoi(fields[1])
 	if err != nil {
 		return 0, 0, err
 	}
@@ -89,7 +144,7 @@ func decodeSize(scanner *bufio.Scanner) (width, height int, err error) {
 	return width, height, nil
 }
 
-func decodeHints(scanner *bufio.Scanner) ([]int, error) {
+func decodeHints(scanner *lineScanner) ([]int, error) {
 	if !scanner.Scan() {
 		return nil, ErrUnexpectedEOF{}
 	}
@@ -101,7 +156,7 @@ func decodeHints(scanner *bufio.Scanner) ([]int, error) {
 	fields := strings.Fields(line)
 	hints := make([]int, len(fields))
 	for i, field := range fields {
-		hint, err := strconv.Atoi(field)
+		hint, err := atoi(field)
 		if err != nil {
 			return nil, err
 		}
@@ -113,30 +168,97 @@ func decodeHints(scanner *bufio.Scanner) ([]int, error) {
 	return hints, nil
 }
 
-func decodeKnownCell(scanner *bufio.Scanner) (int, int, int, error) {
-	if !scanner.Scan() {
-		return -1, -1, -1, ErrUnexpectedEOF{}
-	}
-	line := scanner.Text()
-	line = strings.TrimSpace(line)
-	if line == "" {
-		return -1, -1, -1, errEmptyLine
-	}
+func decodeKnownCell(line string) (int, int, int, error) {
 	fields := strings.Fields(line)
 	if len(fields) != 3 {
 		return -1, -1, -1, ErrInvalidPosition{}
 	}
-	x, err := strconv.Atoi(fields[0])
-	if err != nil || x < 0 {
+	x, err := atoi(fields[0])
+	if err != nil {
 		return -1, -1, -1, err
 	}
-	y, err := strconv.Atoi(fields[1])
-	if err != nil || y < 0 {
+	y, err := atoi(fields[1])
+	if err != nil {
 		return -1, -1, -1, err
 	}
+	if x < 0 || y < 0 {
+		return -1, -1, -1, ErrInvalidPosition{}
+	}
 	v, err := strconv.Atoi(fields[2])
 	if err != nil || (v != 0 && v != 1) {
 		return -1, -1, -1, ErrInvalidValue{expected: "0 or 1", actual: fields[2]}
 	}
 	return x, y, v, nil
 }
+
+// atoi is strconv.Atoi with an error that names the offending field.
+func atoi(field string) (int, error) {
+	v, err := strconv.Atoi(field)
+	if err != nil {
+		return 0, ErrInvalidValue{expected: "a number", actual: field}
+	}
+	return v, nil
+}
+
+// isGridRow tells a row of the grid section, like "#.X..##", from an "x y v"
+// known cell line.
+func isGridRow(line string) bool {
+	return strings.Trim(line, gridChars+"x") == ""
+}
+
+// decodeGridRow sets the known cells of row y from a grid row, where "#" is
+// filled, "X" crossed and "." empty.
+func decodeGridRow(b *board.Board, y int, line string) error {
+	width, height := b.Size()
+	if y >= height {
+		return ErrInvalidValue{expected: fmt.Sprintf("at most %d grid rows", height), actual: line}
+	}
+	if len(line) != width {
+		return ErrInvalidValue{expected: fmt.Sprintf("a grid row of %d cells", width), actual: line}
+	}
+	for x, c := range line {
+		switch c {
+		case '#':
+			b.Set(x, y, board.Filled)
+		case 'X', 'x':
+			b.Set(x, y, board.Crossed)
+		}
+	}
+	return nil
+}
+
+// Encode writes the puzzle in the format read by Decode. Known cells are
+// written as a grid section with one row per line, and the section is left
+// out when no cell is known.
+func Encode(w io.Writer, b *board.Board, h *hint.Hints) error {
+	width, height := b.Size()
+	if len(h.Vertical) != width || len(h.Horizontal) != height {
+		return ErrInvalidSize{}
+	}
+	bw := bufio.NewWriter(w)
+	bw.WriteString(strconv.Itoa(width) + " " + strconv.Itoa(height) + "\n")
+	for _, hints := range append(slices.Clone(h.Vertical), h.Horizontal...) {
+		bw.WriteString(encodeHints(hints) + "\n")
+	}
+	if b.Count(board.Empty) == width*height {
+		return bw.Flush()
+	}
+	for y := 0; y < height; y++ {
+		for _, cell := range b.Row(y) {
+			bw.WriteByte(gridChars[cell])
+		}
+		bw.WriteString("\n")
+	}
+	return bw.Flush()
+}
+
+func encodeHints(hints []int) string {
+	if len(hints) == 0 {
+		return "0"
+	}
+	fields := make([]string, len(hints))
+	for i, hint := range hints {
+		fields[i] = strconv.Itoa(hint)
+	}
+	return strings.Join(fields, " ")
+}
@@ -0,0 +1,109 @@
+package encoding
+
+import (
+	"bytes"
+	"nonogram/board"
+	"nonogram/hint"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestEncode(t *testing.T) {
+	b := board.New(3, 2)
+	b.Set(0, 0, board.Filled)
+	b.Set(2, 0, board.Crossed)
+	b.Set(1, 1, board.Filled)
+	h := hint.New([][]int{{1}, {1}, {0}}, [][]int{{1}, {1}})
+
+	w := new(bytes.Buffer)
+	require.NoError(t, Encode(w, b, h))
+	require.Equal(t, "3 2\n1\n1\n0\n1\n1\n#.X\n.#.\n", w.String())
+
+	decoded, decodedHints, err := Decode(w)
+	require.NoError(t, err)
+	require.Equal(t, b, decoded)
+	require.Equal(t, h, decodedHints)
+
+	require.ErrorAs(t, Encode(w, board.New(2, 2), h), &ErrInvalidSize{})
+
+	w.Reset()
+	require.NoError(t, Encode(w, board.New(3, 2), h))
+	require.Equal(t, "3 2\n1\n1\n0\n1\n1\n", w.String())
+}
+
+func TestDecodeGrid(t *testing.T) {
+	expected := board.New(3, 2)
+	expected.SetRow(0, board.Filled, board.Empty, board.Crossed)
+	expected.SetRow(1, board.Empty, board.Filled, board.Crossed)
+
+	b, _, err := Decode(strings.NewReader("3 2\n1\n1\n0\n1\n1\n\n#.X\n.#x\n"))
+	require.NoError(t, err)
+	require.Equal(t, expected, b)
+
+	b, _, err = Decode(strings.NewReader("3 2\n1\n1\n0\n1\n1\n#.X\n1 1 1\n2 1 0\n"))
+	require.NoError(t, err)
+	require.Equal(t, expected, b)
+
+	_, _, err = Decode(strings.NewReader("3 2\n1\n1\n0\n1\n1\n#.\n"))
+	require.ErrorAs(t, err, &ErrInvalidValue{})
+	_, _, err = Decode(strings.NewReader("3 2\n1\n1\n0\n1\n1\n...\n...\n...\n"))
+	require.ErrorAs(t, err, &ErrInvalidValue{})
+}
+
+func TestRoundTrip(t *testing.T) {
+	puzzles := []string{
+		"1 1\n0\n0\n",
+		"2 3\n1 1\n2\n1\n1\n2\n0 0 1\n1 0 0\n",
+		"5 5\n5\n1 1\n1 1 1\n1 1\n5\n5\n1 1\n1 1 1\n1 1\n5\n0 2 1\n4 4 1\n1 2 0\n",
+		"2 2\n1\n1\n1\n1\n#X\n..\n",
+	}
+	for _, puzzle := range puzzles {
+		b, h, err := Decode(strings.NewReader(puzzle))
+		require.NoError(t, err)
+		w := new(bytes.Buffer)
+		require.NoError(t, Encode(w, b, h))
+		again, againHints, err := Decode(bytes.NewReader(w.Bytes()))
+		require.NoError(t, err)
+		require.Equal(t, b, again, puzzle)
+		require.Equal(t, h, againHints, puzzle)
+	}
+}
+
+func TestDecodeErrors(t *testing.T) {
+	const clues = "2 2\n1\n1\n\n1\n1\n"
+	tests := []struct {
+		input   string
+		line    int
+		text    string
+		section Section
+		err     error
+	}{
+		{input: "2\n", line: 1, text: "2", section: SectionSize, err: &ErrInvalidSize{}},
+		{input: "2 b\n", line: 1, text: "2 b", section: SectionSize, err: &ErrInvalidValue{}},
+		{input: "2 2\n1\n-1\n", line: 3, text: "-1", section: SectionColumnClues, err: &ErrInvalidHints{}},
+		{input: "2 2\n1\n1\n\n1 a\n", line: 5, text: "1 a", section: SectionRowClues, err: &ErrInvalidValue{}},
+		{input: "2 2\n1\n1\n1\n", line: 5, text: "", section: SectionRowClues, err: &ErrUnexpectedEOF{}},
+		{input: clues + "0 -1 1\n", line: 7, text: "0 -1 1", section: SectionKnownCells, err: &ErrInvalidPosition{}},
+		{input: clues + "2 0 1\n", line: 7, text: "2 0 1", section: SectionKnownCells, err: &ErrInvalidPosition{}},
+		{input: clues + "#.\n0 0 2\n", line: 8, text: "0 0 2", section: SectionKnownCells, err: &ErrInvalidValue{}},
+	}
+	for _, test := range tests {
+		_, _, err := Decode(strings.NewReader(test.input))
+		var parse ErrParse
+		require.ErrorAs(t, err, &parse, test.input)
+		require.Equal(t, test.line, parse.Line, test.input)
+		require.Equal(t, test.text, parse.Text, test.input)
+		require.Equal(t, test.section, parse.Section, test.input)
+		require.ErrorAs(t, err, test.err, test.input)
+	}
+
+	_, _, err := Decode(strings.NewReader("2 2\n1\n-1\n"))
+	require.EqualError(t, err, `line 3, column clues: invalid hints: "-1"`)
+
+	_, _, err = Decode(strings.NewReader("2 2\n3\n1\n1\n1\n0 0 0\n"))
+	var invalid hint.ErrInvalidPuzzle
+	require.ErrorAs(t, err, &invalid)
+	require.Len(t, invalid.Problems, 3)
+}
@@ -38,3 +38,43 @@ type ErrInvalidValue struct {
 func (e ErrInvalidValue) Error() string {
 	return "invalid value: expected " + e.expected + ", got " + fmt.Sprintf("%v", e.actual)
 }
+
+type ErrMissingSection struct {
+	name string
+}
+
+func (e ErrMissingSection) Error() string {
+	return "missing " + e.name + " section"
+}
+
+// Section is the part of the input being decoded when an error occurs.
+type Section string
+
+const (
+	SectionSize        Section = "size"
+	SectionColumnClues Section = "column clues"
+	SectionRowClues    Section = "row clues"
+	SectionKnownCells  Section = "known cells"
+	SectionGoal        Section = "goal"
+)
+
+// ErrParse locates a decoding error in the input. Line starts at 1 and Text
+// is empty when the input ended early. Err is one of the other errors of
+// this package.
+type ErrParse struct {
+	Line    int
+	Text    string
+	Section Section
+	Err     error
+}
+
+func (e ErrParse) Error() string {
+	if e.Text == "" {
+		return fmt.Sprintf("line %d, %s: %v", e.Line, e.Section, e.Err)
+	}
+	return fmt.Sprintf("line %d, %s: %v: %q", e.Line, e.Section, e.Err, e.Text)
+}
+
+func (e ErrParse) Unwrap() error {
+	return e.Err
+}
@@ -0,0 +1,231 @@
+package encoding
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"nonogram/board"
+	"nonogram/hint"
+	"strings"
+	"unicode"
+)
+
+// Puzzle is a puzzle together with the metadata of formats that carry it.
+// Board holds the known cells and Solution, when not nil, the goal.
+type Puzzle struct {
+	Board     *board.Board
+	Hints     *hint.Hints
+	Solution  *board.Board
+	Title     string
+	Author    string
+	Copyright string
+	Catalogue string
+
+	// Colors, DefaultColor and BackgroundColor are set for webpbn puzzles.
+	// ColumnColors and RowColors follow the shape of Hints and name the
+	// color of every count. They are nil when all counts have DefaultColor.
+	Colors          []Color
+	DefaultColor    string
+	BackgroundColor string
+	ColumnColors    [][]string
+	RowColors       [][]string
+}
+
+// DecodeNON reads a puzzle in Steve Simpson's .non format. Keywords other
+// than the size, clues, goal and metadata are ignored.
+func DecodeNON(r io.Reader) (*Puzzle, error) {
+	scanner := newLineScanner(r)
+	p := &Puzzle{}
+	width, height := 0, 0
+	var vertical, horizontal [][]int
+	var goal string
+	goalLine := 0
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		keyword, value := line, ""
+		if i := strings.IndexFunc(line, unicode.IsSpace); i >= 0 {
+			keyword, value = line[:i], line[i:]
+		}
+		value = unquote(strings.TrimSpace(value))
+		var err error
+		section := SectionSize
+		switch strings.ToLower(keyword) {
+		case "title":
+			p.Title = value
+		case "by", "author":
+			p.Author = value
+		case "copyright":
+			p.Copyright = value
+		case "catalogue":
+			p.Catalogue = value
+		case "width":
+			width, err = atoi(value)
+			if err == nil && width <= 0 {
+				err = ErrInvalidSize{}
+			}
+		case "height":
+			height, err = atoi(value)
+			if err == nil && height <= 0 {
+				err = ErrInvalidSize{}
+			}
+		case "columns":
+			section = SectionColumnClues
+			if width == 0 {
+				err = ErrMissingSection{name: "width"}
+				break
+			}
+			vertical, err = decodeNONClues(scanner, width)
+		case "rows":
+			section = SectionRowClues
+			if height == 0 {
+				err = ErrMissingSection{name: "height"}
+				break
+			}
+			horizontal, err = decodeNONClues(scanner, height)
+		case "goal":
+			section = SectionGoal
+			goal, goalLine = value, scanner.line
+			if goal == "" && height > 0 {
+				goal, err = decodeNONGoalLines(scanner, height)
+			}
+		}
+		if err != nil {
+			return nil, scanner.wrap(section, err)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if vertical == nil {
+		return nil, ErrMissingSection{name: "columns"}
+	}
+	if horizontal == nil {
+		return nil, ErrMissingSection{name: "rows"}
+	}
+	p.Board = board.New(width, height)
+	p.Hints = hint.New(vertical, horizontal)
+	if goal != "" {
+		solution, err := decodeNONGoal(goal, width, height)
+		if err != nil {
+			return nil, ErrParse{Line: goalLine, Text: goal, Section: SectionGoal, Err: err}
+		}
+		p.Solution = solution
+	}
+	return p, nil
+}
+
+func unquote(value string) string {
+	if len(value) >= 2 && value[0] == '"' && value[len(value)-1] == '"' {
+		return value[1 : len(value)-1]
+	}
+	return value
+}
+
+// decodeNONClues reads the next n lines as comma separated clues. An empty
+// line is an empty clue.
+func decodeNONClues(scanner *lineScanner, n int) ([][]int, error) {
+	clues := make([][]int, n)
+	for i := range clues {
+		if !scanner.Scan() {
+			return nil, ErrUnexpectedEOF{}
+		}
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			clues[i] = []int{0}
+			continue
+		}
+		fields := strings.FieldsFunc(line, func(r rune) bool { return r == ',' || r == ' ' })
+		clues[i] = make([]int, len(fields))
+		for j, field := range fields {
+			v, err := atoi(field)
+			if err != nil {
+				return nil, err
+			}
+			if v < 0 {
+				return nil, ErrInvalidHints{}
+			}
+			clues[i][j] = v
+		}
+	}
+	return clues, nil
+}
+
+func decodeNONGoalLines(scanner *lineScanner, height int) (string, error) {
+	var goal strings.Builder
+	for y := 0; y < height; y++ {
+		if !scanner.Scan() {
+			return "", ErrUnexpectedEOF{}
+		}
+		goal.WriteString(strings.TrimSpace(scanner.Text()))
+	}
+	return goal.String(), nil
+}
+
+func decodeNONGoal(goal string, width, height int) (*board.Board, error) {
+	if len(goal) != width*height {
+		return nil, ErrInvalidValue{expected: fmt.Sprintf("a goal of %d cells", width*height), actual: len(goal)}
+	}
+	b := board.New(width, height)
+	for i, c := range goal {
+		switch c {
+		case '1':
+			b.Set(i%width, i/width, board.Filled)
+		case '0':
+			b.Set(i%width, i/width, board.Crossed)
+		default:
+			return nil, ErrInvalidValue{expected: "0 or 1", actual: string(c)}
+		}
+	}
+	return b, nil
+}
+
+// EncodeNON writes the puzzle in Steve Simpson's .non format, which keeps the
+// clues, the goal and the metadata but neither Board nor colors. It has no
+// escapes, so metadata with quotes or line breaks is rejected.
+func EncodeNON(w io.Writer, p *Puzzle) error {
+	width, height := len(p.Hints.Vertical), len(p.Hints.Horizontal)
+	bw := bufio.NewWriter(w)
+	for _, field := range []struct{ keyword, value string }{
+		{"catalogue", p.Catalogue},
+		{"title", p.Title},
+		{"by", p.Author},
+		{"copyright", p.Copyright},
+	} {
+		if strings.ContainsAny(field.value, "\"\r\n") {
+			return ErrInvalidValue{expected: field.keyword + " without quotes or line breaks", actual: field.value}
+		}
+		if field.value != "" {
+			fmt.Fprintf(bw, "%s \"%s\"\n", field.keyword, field.value)
+		}
+	}
+	fmt.Fprintf(bw, "width %d\nheight %d\n", width, height)
+	bw.WriteString("\nrows\n")
+	for _, hints := range p.Hints.Horizontal {
+		bw.WriteString(strings.ReplaceAll(encodeHints(hints), " ", ",") + "\n")
+	}
+	bw.WriteString("\ncolumns\n")
+	for _, hints := range p.Hints.Vertical {
+		bw.WriteString(strings.ReplaceAll(encodeHints(hints), " ", ",") + "\n")
+	}
+	if p.Solution != nil {
+		solutionWidth, solutionHeight := p.Solution.Size()
+		if solutionWidth != width || solutionHeight != height {
+			return ErrInvalidSize{}
+		}
+		var goal strings.Builder
+		for y := 0; y < height; y++ {
+			for x := 0; x < width; x++ {
+				if p.Solution.Get(x, y) == board.Filled {
+					goal.WriteString("1")
+				} else {
+					goal.WriteString("0")
+				}
+			}
+		}
+		fmt.Fprintf(bw, "\ngoal \"%s\"\n", goal.String())
+	}
+	return bw.Flush()
+}
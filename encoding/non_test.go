@@ -0,0 +1,66 @@
+package encoding
+
+import (
+	"bytes"
+	"nonogram/board"
+	"nonogram/hint"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+const nonPuzzle = `catalogue "webpbn #1"
+title "Demo"
+by "Jan Wolter"
+copyright "© Copyright 2004 by Jan Wolter"
+license CC-BY-3.0
+width 3
+height 2
+
+rows
+1,1
+2
+
+columns
+1
+1
+2
+goal "101011"
+`
+
+func TestDecodeNON(t *testing.T) {
+	p, err := DecodeNON(strings.NewReader(nonPuzzle))
+	require.NoError(t, err)
+	require.Equal(t, "Demo", p.Title)
+	require.Equal(t, "Jan Wolter", p.Author)
+	require.Equal(t, "© Copyright 2004 by Jan Wolter", p.Copyright)
+	require.Equal(t, "webpbn #1", p.Catalogue)
+	require.Equal(t, hint.New([][]int{{1}, {1}, {2}}, [][]int{{1, 1}, {2}}), p.Hints)
+	require.Equal(t, board.New(3, 2), p.Board)
+	require.Equal(t, []board.CellState{board.Filled, board.Crossed, board.Filled}, p.Solution.Row(0))
+	done, err := p.Hints.Check(p.Solution)
+	require.NoError(t, err)
+	require.True(t, done)
+
+	w := new(bytes.Buffer)
+	require.NoError(t, EncodeNON(w, p))
+	again, err := DecodeNON(w)
+	require.NoError(t, err)
+	require.Equal(t, p, again)
+
+	tabs, err := DecodeNON(strings.NewReader(strings.ReplaceAll(nonPuzzle, " ", "\t")))
+	require.NoError(t, err)
+	require.Equal(t, p.Hints, tabs.Hints)
+	require.Equal(t, "webpbn\t#1", tabs.Catalogue)
+
+	p.Title = `The "Demo"`
+	require.ErrorAs(t, EncodeNON(new(bytes.Buffer), p), &ErrInvalidValue{})
+
+	_, err = DecodeNON(strings.NewReader("width 3\nheight 2\nrows\n1\n1\n"))
+	require.ErrorAs(t, err, &ErrMissingSection{})
+	_, err = DecodeNON(strings.NewReader("rows\n1\n"))
+	require.ErrorAs(t, err, &ErrMissingSection{})
+	_, err = DecodeNON(strings.NewReader("width 1\nheight 1\nrows\n1\ncolumns\n1\ngoal 10\n"))
+	require.ErrorAs(t, err, &ErrInvalidValue{})
+}
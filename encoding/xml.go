@@ -0,0 +1,303 @@
+package encoding
+
+import (
+	"encoding/xml"
+	"io"
+	"nonogram/board"
+	"nonogram/hint"
+	"strings"
+)
+
+const (
+	DefaultColor    = "black"
+	BackgroundColor = "white"
+)
+
+// Color is a color of a webpbn puzzle. Char stands for the color in solution
+// images and Value is its hex RGB value, like "000" or "ff0000".
+type Color struct {
+	Name  string
+	Char  string
+	Value string
+}
+
+type xmlPuzzleSet struct {
+	XMLName xml.Name    `xml:"puzzleset"`
+	Puzzles []xmlPuzzle `xml:"puzzle"`
+}
+
+type xmlPuzzle struct {
+	Type            string        `xml:"type,attr,omitempty"`
+	DefaultColor    string        `xml:"defaultcolor,attr,omitempty"`
+	BackgroundColor string        `xml:"backgroundcolor,attr,omitempty"`
+	ID              string        `xml:"id,omitempty"`
+	Title           string        `xml:"title,omitempty"`
+	Author          string        `xml:"author,omitempty"`
+	Copyright       string        `xml:"copyright,omitempty"`
+	Colors          []xmlColor    `xml:"color"`
+	Clues           []xmlClues    `xml:"clues"`
+	Solutions       []xmlSolution `xml:"solution"`
+}
+
+type xmlColor struct {
+	Name  string `xml:"name,attr"`
+	Char  string `xml:"char,attr,omitempty"`
+	Value string `xml:",chardata"`
+}
+
+type xmlClues struct {
+	Type  string    `xml:"type,attr"`
+	Lines []xmlLine `xml:"line"`
+}
+
+type xmlLine struct {
+	Counts []xmlCount `xml:"count"`
+}
+
+type xmlCount struct {
+	Color string `xml:"color,attr,omitempty"`
+	Value int    `xml:",chardata"`
+}
+
+type xmlSolution struct {
+	Type  string `xml:"type,attr,omitempty"`
+	Image string `xml:"image"`
+}
+
+// DecodeXML reads every puzzle of a webpbn XML puzzle set. Cells of any color
+// other than the background are filled in the solution, use Colored to skip
+// the puzzles the solver cannot handle.
+func DecodeXML(r io.Reader) ([]*Puzzle, error) {
+	decoder := xml.NewDecoder(r)
+	decoder.Entity = xml.HTMLEntity
+	set := xmlPuzzleSet{}
+	if err := decoder.Decode(&set); err != nil {
+		return nil, err
+	}
+	puzzles := make([]*Puzzle, len(set.Puzzles))
+	for i, x := range set.Puzzles {
+		p, err := decodeXMLPuzzle(x)
+		if err != nil {
+			return nil, err
+		}
+		puzzles[i] = p
+	}
+	return puzzles, nil
+}
+
+func decodeXMLPuzzle(x xmlPuzzle) (*Puzzle, error) {
+	p := &Puzzle{
+		Title:           strings.TrimSpace(x.Title),
+		Author:          strings.TrimSpace(x.Author),
+		Copyright:       strings.TrimSpace(x.Copyright),
+		Catalogue:       strings.TrimSpace(x.ID),
+		DefaultColor:    x.DefaultColor,
+		BackgroundColor: x.BackgroundColor,
+	}
+	if p.DefaultColor == "" {
+		p.DefaultColor = DefaultColor
+	}
+	if p.BackgroundColor == "" {
+		p.BackgroundColor = BackgroundColor
+	}
+	for _, c := range x.Colors {
+		p.Colors = append(p.Colors, Color{Name: c.Name, Char: c.Char, Value: strings.TrimSpace(c.Value)})
+	}
+
+	var vertical, horizontal [][]int
+	for _, clues := range x.Clues {
+		hints, colors := decodeXMLClues(clues, p.DefaultColor)
+		switch clues.Type {
+		case "columns":
+			vertical, p.ColumnColors = hints, colors
+		case "rows":
+			horizontal, p.RowColors = hints, colors
+		}
+	}
+	if len(vertical) == 0 {
+		return nil, ErrMissingSection{name: "columns"}
+	}
+	if len(horizontal) == 0 {
+		return nil, ErrMissingSection{name: "rows"}
+	}
+	p.Board = board.New(len(vertical), len(horizontal))
+	p.Hints = hint.New(vertical, horizontal)
+
+	for _, solution := range x.Solutions {
+		if solution.Type != "" && solution.Type != "goal" && solution.Type != "solution" {
+			continue
+		}
+		b, err := decodeXMLImage(solution.Image, p.charOf(p.BackgroundColor), len(vertical), len(horizontal))
+		if err != nil {
+			return nil, err
+		}
+		p.Solution = b
+		break
+	}
+	return p, nil
+}
+
+// decodeXMLClues returns the counts of every line and their colors. The
+// colors are nil when every count has the default color.
+func decodeXMLClues(clues xmlClues, defaultColor string) ([][]int, [][]string) {
+	hints := make([][]int, len(clues.Lines))
+	colors := make([][]string, len(clues.Lines))
+	colored := false
+	for i, line := range clues.Lines {
+		hints[i] = []int{}
+		colors[i] = []string{}
+		for _, count := range line.Counts {
+			color := count.Color
+			if color == "" {
+				color = defaultColor
+			}
+			colored = colored || color != defaultColor
+			hints[i] = append(hints[i], count.Value)
+			colors[i] = append(colors[i], color)
+		}
+		if len(hints[i]) == 0 {
+			hints[i] = []int{0}
+			colors[i] = []string{defaultColor}
+		}
+	}
+	if !colored {
+		return hints, nil
+	}
+	return hints, colors
+}
+
+func decodeXMLImage(image, background string, width, height int) (*board.Board, error) {
+	b := board.New(width, height)
+	y := 0
+	for _, line := range strings.Split(image, "\n") {
+		line = strings.Trim(strings.TrimSpace(line), "|")
+		if line == "" {
+			continue
+		}
+		if y >= height || len([]rune(line)) != width {
+			return nil, ErrInvalidSize{}
+		}
+		for x, c := range []rune(line) {
+			if string(c) == background {
+				b.Set(x, y, board.Crossed)
+			} else {
+				b.Set(x, y, board.Filled)
+			}
+		}
+		y++
+	}
+	if y != height {
+		return nil, ErrInvalidSize{}
+	}
+	return b, nil
+}
+
+// Colored reports whether the clues use colors other than the default one.
+func (t *Puzzle) Colored() bool {
+	return t.ColumnColors != nil || t.RowColors != nil
+}
+
+// charOf returns the character of a color in solution images, with the
+// webpbn defaults for black and white.
+func (t *Puzzle) charOf(name string) string {
+	for _, c := range t.Colors {
+		if c.Name == name && c.Char != "" {
+			return c.Char
+		}
+	}
+	switch name {
+	case BackgroundColor:
+		return "."
+	case DefaultColor:
+		return "X"
+	}
+	return ""
+}
+
+// EncodeXML writes the puzzles as a webpbn XML puzzle set. Only the goal is
+// written as a solution; progress in Board is not saved.
+func EncodeXML(w io.Writer, puzzles ...*Puzzle) error {
+	set := xmlPuzzleSet{Puzzles: make([]xmlPuzzle, len(puzzles))}
+	for i, p := range puzzles {
+		x, err := encodeXMLPuzzle(p)
+		if err != nil {
+			return err
+		}
+		set.Puzzles[i] = x
+	}
+	if _, err := io.WriteString(w, xml.Header+"<!DOCTYPE pbn SYSTEM \"https://webpbn.com/pbn-0.3.dtd\">\n"); err != nil {
+		return err
+	}
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(set); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+func encodeXMLPuzzle(p *Puzzle) (xmlPuzzle, error) {
+	x := xmlPuzzle{
+		Type:            "grid",
+		DefaultColor:    p.DefaultColor,
+		BackgroundColor: p.BackgroundColor,
+		ID:              p.Catalogue,
+		Title:           p.Title,
+		Author:          p.Author,
+		Copyright:       p.Copyright,
+		Clues: []xmlClues{
+			encodeXMLClues("columns", p.Hints.Vertical, p.ColumnColors),
+			encodeXMLClues("rows", p.Hints.Horizontal, p.RowColors),
+		},
+	}
+	for _, c := range p.Colors {
+		x.Colors = append(x.Colors, xmlColor{Name: c.Name, Char: c.Char, Value: c.Value})
+	}
+	if p.Solution != nil {
+		width, height := p.Solution.Size()
+		if width != len(p.Hints.Vertical) || height != len(p.Hints.Horizontal) {
+			return xmlPuzzle{}, ErrInvalidSize{}
+		}
+		background := p.charOf(p.BackgroundColor)
+		if background == "" {
+			background = "."
+		}
+		filled := p.charOf(p.DefaultColor)
+		if filled == "" {
+			filled = "X"
+		}
+		var image strings.Builder
+		image.WriteString("\n")
+		for y := 0; y < height; y++ {
+			image.WriteString("|")
+			for x := 0; x < width; x++ {
+				if p.Solution.Get(x, y) == board.Filled {
+					image.WriteString(filled)
+				} else {
+					image.WriteString(background)
+				}
+			}
+			image.WriteString("|\n")
+		}
+		x.Solutions = []xmlSolution{{Type: "goal", Image: image.String()}}
+	}
+	return x, nil
+}
+
+func encodeXMLClues(kind string, hints [][]int, colors [][]string) xmlClues {
+	clues := xmlClues{Type: kind, Lines: make([]xmlLine, len(hints))}
+	for i, line := range hints {
+		for j, count := range line {
+			if count == 0 {
+				continue
+			}
+			c := xmlCount{Value: count}
+			if colors != nil && i < len(colors) && j < len(colors[i]) {
+				c.Color = colors[i][j]
+			}
+			clues.Lines[i].Counts = append(clues.Lines[i].Counts, c)
+		}
+	}
+	return clues
+}
@@ -0,0 +1,85 @@
+package encoding
+
+import (
+	"bytes"
+	"nonogram/board"
+	"nonogram/hint"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+const xmlPuzzles = `<?xml version="1.0"?>
+<!DOCTYPE pbn SYSTEM "https://webpbn.com/pbn-0.3.dtd">
+<puzzleset>
+<puzzle type="grid" defaultcolor="black">
+<source>webpbn.com</source>
+<id>#1</id>
+<title>Demo</title>
+<author>Jan Wolter</author>
+<copyright>&copy; Copyright 2004 by Jan Wolter</copyright>
+<color name="white" char=".">fff</color>
+<color name="black" char="X">000</color>
+<clues type="columns">
+<line><count>1</count></line>
+<line><count>1</count></line>
+<line><count>2</count></line>
+</clues>
+<clues type="rows">
+<line><count>1</count><count>1</count></line>
+<line><count>2</count></line>
+</clues>
+<solution type="goal">
+<image>
+|X.X|
+|.XX|
+</image>
+</solution>
+</puzzle>
+<puzzle type="grid" defaultcolor="black">
+<color name="white" char=".">fff</color>
+<color name="black" char="X">000</color>
+<color name="red" char="r">f00</color>
+<clues type="columns">
+<line><count color="red">1</count></line>
+<line/>
+</clues>
+<clues type="rows">
+<line><count color="red">1</count></line>
+</clues>
+</puzzle>
+</puzzleset>
+`
+
+func TestDecodeXML(t *testing.T) {
+	puzzles, err := DecodeXML(strings.NewReader(xmlPuzzles))
+	require.NoError(t, err)
+	require.Len(t, puzzles, 2)
+
+	p := puzzles[0]
+	require.Equal(t, "Demo", p.Title)
+	require.Equal(t, "© Copyright 2004 by Jan Wolter", p.Copyright)
+	require.Equal(t, "#1", p.Catalogue)
+	require.False(t, p.Colored())
+	require.Equal(t, hint.New([][]int{{1}, {1}, {2}}, [][]int{{1, 1}, {2}}), p.Hints)
+	done, err := p.Hints.Check(p.Solution)
+	require.NoError(t, err)
+	require.True(t, done)
+
+	colored := puzzles[1]
+	require.True(t, colored.Colored())
+	require.Equal(t, [][]int{{1}, {0}}, colored.Hints.Vertical)
+	require.Equal(t, [][]string{{"red"}, {"black"}}, colored.ColumnColors)
+	require.Equal(t, board.New(2, 1), colored.Board)
+	require.Nil(t, colored.Solution)
+
+	w := new(bytes.Buffer)
+	require.NoError(t, EncodeXML(w, puzzles...))
+	again, err := DecodeXML(w)
+	require.NoError(t, err)
+	require.Equal(t, puzzles, again)
+
+	_, err = DecodeXML(strings.NewReader("<puzzleset><puzzle></puzzle></puzzleset>"))
+	require.ErrorAs(t, err, &ErrMissingSection{})
+}
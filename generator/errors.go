@@ -0,0 +1,28 @@
+package generator
+
+import "fmt"
+
+type ErrInvalidSize struct {
+	width  int
+	height int
+}
+
+func (e ErrInvalidSize) Error() string {
+	return fmt.Sprintf("invalid size %dx%d", e.width, e.height)
+}
+
+type ErrInvalidDensity struct {
+	density float64
+}
+
+func (e ErrInvalidDensity) Error() string {
+	return fmt.Sprintf("invalid density %v, expected a value between 0 and 1", e.density)
+}
+
+type ErrNoPuzzle struct {
+	attempts int
+}
+
+func (e ErrNoPuzzle) Error() string {
+	return fmt.Sprintf("no puzzle with a unique solution and the requested difficulty after %d attempts", e.attempts)
+}
@@ -0,0 +1,195 @@
+package generator
+
+import (
+	"context"
+	"math/rand/v2"
+	"nonogram/board"
+	"nonogram/hint"
+	"nonogram/solver"
+	"time"
+)
+
+const (
+	DefaultDensity  = 0.5
+	DefaultAttempts = 100
+
+	// adjustments is how many cells are flipped to break the ambiguity of a
+	// board before starting over with a new one.
+	adjustments = 20
+	// checkTimeout bounds the search for a second solution of one board.
+	checkTimeout = 2 * time.Second
+)
+
+type Options struct {
+	Width  int
+	Height int
+	// Density is the fraction of filled cells, DefaultDensity when zero.
+	Density float64
+	// Pattern seeds the solution: its filled and crossed cells are kept and
+	// only its empty cells are random. It must be Width by Height.
+	Pattern *board.Board
+	// MinScore and MaxScore bound the difficulty score of the puzzle, zero
+	// means no bound.
+	MinScore int
+	MaxScore int
+	// Attempts is the number of boards tried, DefaultAttempts when zero.
+	Attempts int
+	// Rand is the source of randomness, the global one when nil.
+	Rand *rand.Rand
+}
+
+type Puzzle struct {
+	Solution   *board.Board
+	Hints      *hint.Hints
+	Difficulty solver.Difficulty
+}
+
+// Generate creates a random puzzle with a unique solution. When the puzzle of
+// a random board is ambiguous it flips a cell where two solutions differ and
+// tries again, and after a few flips it starts over with a new board.
+func Generate(ctx context.Context, opts Options) (*Puzzle, error) {
+	if opts.Width <= 0 || opts.Height <= 0 {
+		return nil, ErrInvalidSize{width: opts.Width, height: opts.Height}
+	}
+	if opts.Pattern != nil {
+		width, height := opts.Pattern.Size()
+		if width != opts.Width || height != opts.Height {
+			return nil, ErrInvalidSize{width: width, height: height}
+		}
+	}
+	if opts.Density == 0 {
+		opts.Density = DefaultDensity
+	}
+	if opts.Density < 0 || opts.Density > 1 {
+		return nil, ErrInvalidDensity{density: opts.Density}
+	}
+	if opts.Attempts <= 0 {
+		opts.Attempts = DefaultAttempts
+	}
+	intn := rand.IntN
+	float := rand.Float64
+	if opts.Rand != nil {
+		intn = opts.Rand.IntN
+		float = opts.Rand.Float64
+	}
+
+	for attempt := 0; attempt < opts.Attempts; attempt++ {
+		solution := random(opts, float)
+		for i := 0; i <= adjustments; i++ {
+			if err := context.Cause(ctx); err != nil {
+				return nil, err
+			}
+			h := hint.FromBoard(solution)
+			other, err := second(ctx, solution, h)
+			if err != nil {
+				break
+			}
+			if other == nil {
+				puzzle := &Puzzle{Solution: solution, Hints: h}
+				if accept(ctx, opts, puzzle) {
+					return puzzle, nil
+				}
+				break
+			}
+			if !flip(opts, solution, other, intn) {
+				break
+			}
+		}
+	}
+	if err := context.Cause(ctx); err != nil {
+		return nil, err
+	}
+	return nil, ErrNoPuzzle{attempts: opts.Attempts}
+}
+
+func random(opts Options, float func() float64) *board.Board {
+	b := board.New(opts.Width, opts.Height)
+	for y := 0; y < opts.Height; y++ {
+		for x := 0; x < opts.Width; x++ {
+			state := board.Crossed
+			if opts.Pattern != nil && opts.Pattern.Get(x, y) != board.Empty {
+				state = opts.Pattern.Get(x, y)
+			} else if float() < opts.Density {
+				state = board.Filled
+			}
+			b.Set(x, y, state)
+		}
+	}
+	return b
+}
+
+// second returns a solution of the hints other than solution, nil when the
+// solution is unique, or an error when the search is cut short.
+func second(ctx context.Context, solution *board.Board, h *hint.Hints) (*board.Board, error) {
+	ctx, cancel := context.WithTimeout(ctx, checkTimeout)
+	defer cancel()
+
+	width, height := solution.Size()
+	solutions, _, err := solver.SolveAll(ctx, board.New(width, height), h, 2)
+	if err != nil {
+		return nil, err
+	}
+	if len(solutions) < 2 {
+		return nil, nil
+	}
+	if sameFilled(solutions[0], solution) {
+		return solutions[1], nil
+	}
+	return solutions[0], nil
+}
+
+func sameFilled(a, b *board.Board) bool {
+	width, height := a.Size()
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			if (a.Get(x, y) == board.Filled) != (b.Get(x, y) == board.Filled) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// flip toggles a random cell of solution that differs in other and is not
+// fixed by the pattern. It returns false when there is no such cell.
+func flip(opts Options, solution, other *board.Board, intn func(int) int) bool {
+	cells := [][2]int{}
+	for y := 0; y < opts.Height; y++ {
+		for x := 0; x < opts.Width; x++ {
+			if (solution.Get(x, y) == board.Filled) == (other.Get(x, y) == board.Filled) {
+				continue
+			}
+			if opts.Pattern != nil && opts.Pattern.Get(x, y) != board.Empty {
+				continue
+			}
+			cells = append(cells, [2]int{x, y})
+		}
+	}
+	if len(cells) == 0 {
+		return false
+	}
+	cell := cells[intn(len(cells))]
+	if solution.Get(cell[0], cell[1]) == board.Filled {
+		solution.Set(cell[0], cell[1], board.Crossed)
+	} else {
+		solution.Set(cell[0], cell[1], board.Filled)
+	}
+	return true
+}
+
+// accept rates the puzzle and reports whether it is within the requested
+// difficulty.
+func accept(ctx context.Context, opts Options, puzzle *Puzzle) bool {
+	difficulty, err := solver.Rate(ctx, board.New(opts.Width, opts.Height), puzzle.Hints)
+	if err != nil {
+		return false
+	}
+	puzzle.Difficulty = difficulty
+	if opts.MinScore > 0 && difficulty.Score < opts.MinScore {
+		return false
+	}
+	if opts.MaxScore > 0 && difficulty.Score > opts.MaxScore {
+		return false
+	}
+	return true
+}
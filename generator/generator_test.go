@@ -0,0 +1,62 @@
+package generator
+
+import (
+	"context"
+	"math/rand/v2"
+	"nonogram/board"
+	"nonogram/hint"
+	"nonogram/solver"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerate(t *testing.T) {
+	r := rand.New(rand.NewPCG(1, 2))
+	puzzle, err := Generate(context.Background(), Options{Width: 10, Height: 8, Rand: r})
+	require.NoError(t, err)
+	require.Empty(t, hint.FromBoard(puzzle.Solution).Diff(puzzle.Hints))
+	require.NotZero(t, puzzle.Difficulty.Score)
+
+	solutions, _, err := solver.SolveAll(context.Background(), board.New(10, 8), puzzle.Hints, 0)
+	require.NoError(t, err)
+	require.Len(t, solutions, 1)
+
+	pattern := board.New(5, 5)
+	for i := 0; i < 5; i++ {
+		pattern.Set(i, i, board.Filled)
+		pattern.Set(4-i, i, board.Crossed)
+	}
+	pattern.Set(2, 2, board.Filled)
+	puzzle, err = Generate(context.Background(), Options{Width: 5, Height: 5, Pattern: pattern, MaxScore: 199, Rand: r})
+	require.NoError(t, err)
+	require.LessOrEqual(t, puzzle.Difficulty.Score, 199)
+	for i := 0; i < 5; i++ {
+		require.Equal(t, board.Filled, puzzle.Solution.Get(i, i))
+		if i != 2 {
+			require.Equal(t, board.Crossed, puzzle.Solution.Get(4-i, i))
+		}
+	}
+
+	_, err = Generate(context.Background(), Options{Width: 0, Height: 5})
+	require.ErrorAs(t, err, &ErrInvalidSize{})
+	_, err = Generate(context.Background(), Options{Width: 5, Height: 5, Density: 2})
+	require.ErrorAs(t, err, &ErrInvalidDensity{})
+	_, err = Generate(context.Background(), Options{Width: 5, Height: 5, MinScore: 1000, Attempts: 3, Rand: r})
+	require.ErrorAs(t, err, &ErrNoPuzzle{})
+}
+
+func TestSecond(t *testing.T) {
+	for _, filled := range [][2]int{{0, 1}, {1, 0}} {
+		solution := board.New(2, 2)
+		for y := 0; y < 2; y++ {
+			solution.SetRow(y, board.Crossed, board.Crossed)
+			solution.Set(filled[y], y, board.Filled)
+		}
+		other, err := second(context.Background(), solution, hint.FromBoard(solution))
+		require.NoError(t, err)
+		require.NotNil(t, other)
+		require.False(t, sameFilled(solution, other))
+		require.True(t, flip(Options{Width: 2, Height: 2}, solution.Clone(), other, func(n int) int { return 0 }))
+	}
+}
@@ -1,6 +1,10 @@
 package hint
 
-import "fmt"
+import (
+	"fmt"
+	"nonogram/board"
+	"strings"
+)
 
 type ErrInvalidBoardSize struct {
 	expected [2]int
@@ -23,11 +27,79 @@ func (e ErrMissingHints) Error() string {
 type ErrInvalid struct {
 	isRow bool
 	index int
+	line  []board.CellState
+	hint  []int
 }
 
 func (e ErrInvalid) Error() string {
-	if e.isRow {
-		return fmt.Sprintf("invalid row at index %d", e.index)
+	return fmt.Sprintf("invalid %s at index %d: %s does not fit %v", direction(e.isRow), e.index, cellsString(e.line), e.hint)
+}
+
+func cellsString(cells []board.CellState) string {
+	var r strings.Builder
+	for _, cell := range cells {
+		switch cell {
+		case board.Empty:
+			r.WriteString(".")
+		case board.Filled:
+			r.WriteString("█")
+		case board.Crossed:
+			r.WriteString("X")
+		}
+	}
+	return r.String()
+}
+
+// ErrInvalidPuzzle lists every problem found by Validate.
+type ErrInvalidPuzzle struct {
+	Problems []error
+}
+
+func (e ErrInvalidPuzzle) Error() string {
+	messages := make([]string, len(e.Problems))
+	for i, problem := range e.Problems {
+		messages[i] = problem.Error()
+	}
+	return "invalid puzzle: " + strings.Join(messages, "; ")
+}
+
+func (e ErrInvalidPuzzle) Unwrap() []error {
+	return e.Problems
+}
+
+type ErrClueTooLong struct {
+	isRow  bool
+	index  int
+	hint   []int
+	length int
+}
+
+func (e ErrClueTooLong) Error() string {
+	return fmt.Sprintf("%s %d: clue %v does not fit in %d cells", direction(e.isRow), e.index, e.hint, e.length)
+}
+
+type ErrInvalidZero struct {
+	isRow bool
+	index int
+	hint  []int
+}
+
+func (e ErrInvalidZero) Error() string {
+	return fmt.Sprintf("%s %d: clue %v mixes 0 with other counts", direction(e.isRow), e.index, e.hint)
+}
+
+type ErrTotalsMismatch struct {
+	columns int
+	rows    int
+}
+
+func (e ErrTotalsMismatch) Error() string {
+	return fmt.Sprintf("column clues add up to %d filled cells but row clues add up to %d", e.columns, e.rows)
+}
+
+func direction(isRow bool) string {
+	if isRow {
+		return "row"
 	}
-	return fmt.Sprintf("invalid column at index %d", e.index)
+	return "column"
 }
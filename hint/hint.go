@@ -1,7 +1,10 @@
 package hint
 
 import (
+	"fmt"
 	"nonogram/board"
+	"nonogram/line"
+	"slices"
 	"strconv"
 	"strings"
 )
@@ -13,9 +16,97 @@ func New(vertical, horizontal [][]int) *Hints {
 	}
 }
 
+// Hints are the clues of a puzzle. In JSON the column clues, from left to
+// right, are "columns" and the row clues, from top to bottom, are "rows":
+//
+//	{"columns": [[1], [1], [2]], "rows": [[1, 1], [2]]}
 type Hints struct {
-	Vertical   [][]int
-	Horizontal [][]int
+	Vertical   [][]int `json:"columns"`
+	Horizontal [][]int `json:"rows"`
+}
+
+// FromBoard returns the hints of a solved board. Crossed and empty cells are
+// both blank.
+func FromBoard(b *board.Board) *Hints {
+	width, height := b.Size()
+	vertical := make([][]int, width)
+	for x := range vertical {
+		vertical[x] = clue(b.Column(x))
+	}
+	horizontal := make([][]int, height)
+	for y := range horizontal {
+		horizontal[y] = clue(b.Row(y))
+	}
+	return New(vertical, horizontal)
+}
+
+func clue(cells []board.CellState) []int {
+	clue := []int{}
+	run := 0
+	for _, cell := range cells {
+		if cell == board.Filled {
+			run++
+			continue
+		}
+		if run > 0 {
+			clue = append(clue, run)
+			run = 0
+		}
+	}
+	if run > 0 {
+		clue = append(clue, run)
+	}
+	if len(clue) == 0 {
+		clue = append(clue, 0)
+	}
+	return clue
+}
+
+// Difference is a line whose clue is not the same in two Hints. A line that
+// only exists in one of them has a nil clue in the other.
+type Difference struct {
+	IsRow bool
+	Index int
+	A     []int
+	B     []int
+}
+
+func (t Difference) String() string {
+	direction := "column"
+	if t.IsRow {
+		direction = "row"
+	}
+	return fmt.Sprintf("%s %d: %v != %v", direction, t.Index, t.A, t.B)
+}
+
+// Diff returns the lines whose clues differ between t and other, columns
+// first. A missing clue and [0] are the same.
+func (t *Hints) Diff(other *Hints) []Difference {
+	differences := diff(false, t.Vertical, other.Vertical)
+	return append(differences, diff(true, t.Horizontal, other.Horizontal)...)
+}
+
+func diff(isRow bool, a, b [][]int) []Difference {
+	differences := []Difference{}
+	for i := 0; i < max(len(a), len(b)); i++ {
+		var clueA, clueB []int
+		if i < len(a) {
+			clueA = a[i]
+		}
+		if i < len(b) {
+			clueB = b[i]
+		}
+		if i < len(a) && i < len(b) && slices.Equal(normalize(clueA), normalize(clueB)) {
+			continue
+		}
+		differences = append(differences, Difference{IsRow: isRow, Index: i, A: clueA, B: clueB})
+	}
+	return differences
+}
+
+// normalize drops the zeros of a clue, so an empty line is always empty.
+func normalize(clue []int) []int {
+	return slices.DeleteFunc(slices.Clone(clue), func(v int) bool { return v == 0 })
 }
 
 func (t *Hints) Check(b *board.Board) (bool, error) {
@@ -31,105 +122,25 @@ func (t *Hints) Check(b *board.Board) (bool, error) {
 		if len(hint) == 0 {
 			return false, ErrMissingHints{direction: "vertical", index: x}
 		}
-		empty, filled := t.runs(b, x, 0, x+1, height)
-		if len(filled) == 0 && len(empty) == 1 && empty[0] == height {
-			continue
+		cells := b.Column(x)
+		if !line.Fits(cells, hint) {
+			return false, ErrInvalid{isRow: false, index: x, line: cells, hint: hint}
 		}
-		if len(empty) == 0 {
-			if len(filled) != len(hint) && len(filled) != 0 && hint[0] != 0 {
-				return false, ErrInvalid{isRow: false, index: x}
-			}
-			for i, count := range filled {
-				if count != hint[i] {
-					return false, ErrInvalid{isRow: false, index: x}
-				}
-			}
-			continue
-		}
-
-		totalAvailable := sum(empty) + sum(filled)
-		totalHint := sum(hint)
-		if totalAvailable < totalHint {
-			return false, ErrInvalid{isRow: false, index: x}
-		}
-
-		hasEmpty = true
+		hasEmpty = hasEmpty || slices.Contains(cells, board.Empty)
 	}
 	for y, hint := range t.Horizontal {
 		if len(hint) == 0 {
 			return false, ErrMissingHints{direction: "horizontal", index: y}
 		}
-		empty, filled := t.runs(b, 0, y, width, y+1)
-		if len(filled) == 0 && len(empty) == 1 && empty[0] == width {
-			continue
-		}
-		if len(empty) == 0 {
-			if len(filled) != len(hint) && len(filled) != 0 && hint[0] != 0 {
-				return false, ErrInvalid{isRow: true, index: y}
-			}
-			for i, count := range filled {
-				if count != hint[i] {
-					return false, ErrInvalid{isRow: true, index: y}
-				}
-			}
-			continue
-		}
-
-		totalFilled := max(filled)
-		totalAvailable := sum(empty) + sum(filled)
-		totalHint := sum(hint)
-		if totalAvailable < totalHint || totalFilled > totalHint {
-			return false, ErrInvalid{isRow: true, index: y}
+		cells := b.Row(y)
+		if !line.Fits(cells, hint) {
+			return false, ErrInvalid{isRow: true, index: y, line: cells, hint: hint}
 		}
-
-		hasEmpty = true
+		hasEmpty = hasEmpty || slices.Contains(cells, board.Empty)
 	}
 	return !hasEmpty, nil
 }
 
-func (t *Hints) runs(b *board.Board, x1, y1, x2, y2 int) (empty, filled []int) {
-	if x2-x1 > 1 && y2-y1 > 1 {
-		return nil, nil
-	}
-	first := true
-	last := board.Empty
-	count := 0
-	for y := y1; y < y2; y++ {
-		for x := x1; x < x2; x++ {
-			cell := b.Get(x, y)
-			if first {
-				first = false
-				last = cell
-				count = 1
-				continue
-			}
-			if cell == last {
-				count++
-				continue
-			}
-			switch last {
-			case board.Empty:
-				empty = append(empty, count)
-			case board.Filled:
-				filled = append(filled, count)
-			case board.Crossed:
-			}
-			last = cell
-			count = 1
-		}
-	}
-	if count > 0 {
-		switch last {
-		case board.Empty:
-			empty = append(empty, count)
-		case board.Filled:
-			filled = append(filled, count)
-		case board.Crossed:
-		}
-	}
-	return empty, filled
-}
-
 func (t Hints) String() string {
 	var r strings.Builder
 	r.WriteString("Vertical:\n")
@@ -154,24 +165,3 @@ func (t Hints) String() string {
 	}
 	return r.String()
 }
-
-func sum(s []int) int {
-	total := 0
-	for _, v := range s {
-		total += v
-	}
-	return total
-}
-
-func max(s []int) int {
-	if len(s) == 0 {
-		return 0
-	}
-	v := s[0]
-	for i := 1; i < len(s); i++ {
-		if s[i] > v {
-			v = s[i]
-		}
-	}
-	return v
-}
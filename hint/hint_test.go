@@ -0,0 +1,73 @@
+package hint
+
+import (
+	"errors"
+	"nonogram/board"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheck(t *testing.T) {
+	h := New([][]int{{1}, {1}, {0}}, [][]int{{1, 1}, {0}, {0}})
+
+	b := board.New(3, 3)
+	done, err := h.Check(b)
+	require.NoError(t, err)
+	require.False(t, done)
+
+	b.SetRow(0, board.Filled, board.Filled, board.Empty)
+	_, err = h.Check(b)
+	var invalid ErrInvalid
+	require.True(t, errors.As(err, &invalid))
+	require.Equal(t, "invalid row at index 0: ██. does not fit [1 1]", invalid.Error())
+
+	b = board.New(3, 3)
+	b.SetRow(0, board.Filled, board.Crossed, board.Filled)
+	_, err = h.Check(b)
+	require.True(t, errors.As(err, &invalid))
+	require.Equal(t, "invalid column at index 2: █.. does not fit [0]", invalid.Error())
+}
+
+func TestFromBoard(t *testing.T) {
+	b := board.New(3, 2)
+	b.SetRow(0, board.Filled, board.Crossed, board.Filled)
+	b.SetRow(1, board.Filled, board.Filled, board.Empty)
+	h := FromBoard(b)
+	require.Equal(t, New([][]int{{2}, {1}, {1}}, [][]int{{1, 1}, {2}}), h)
+	done, err := h.Check(b)
+	require.NoError(t, err)
+	require.False(t, done)
+
+	require.Empty(t, h.Diff(New([][]int{{2}, {1}, {1}}, [][]int{{1, 1}, {2}})))
+	require.Empty(t, FromBoard(board.New(1, 1)).Diff(New([][]int{{}}, [][]int{{0}})))
+
+	differences := h.Diff(New([][]int{{2}, {1}}, [][]int{{1, 1}, {1}}))
+	require.Equal(t, []Difference{
+		{IsRow: false, Index: 2, A: []int{1}},
+		{IsRow: true, Index: 1, A: []int{2}, B: []int{1}},
+	}, differences)
+	require.Equal(t, "row 1: [2] != [1]", differences[1].String())
+}
+
+func TestValidate(t *testing.T) {
+	h := New([][]int{{1}, {1}, {2}}, [][]int{{1, 1}, {2}})
+	require.NoError(t, h.Validate(nil))
+	require.NoError(t, h.Validate(board.New(3, 2)))
+
+	b := board.New(3, 2)
+	b.SetRow(0, board.Filled, board.Filled, board.Empty)
+	b.SetRow(1, board.Crossed, board.Crossed, board.Crossed)
+	var invalid ErrInvalidPuzzle
+	require.ErrorAs(t, h.Validate(b), &invalid)
+	require.Len(t, invalid.Problems, 3)
+	require.ErrorAs(t, h.Validate(b), &ErrInvalid{})
+
+	h = New([][]int{{1, 0}, {3}, {}}, [][]int{{1, 1}, {1}})
+	require.ErrorAs(t, h.Validate(nil), &invalid)
+	require.Equal(t, "invalid puzzle: column 0: clue [1 0] mixes 0 with other counts; column 1: clue [3] does not fit in 2 cells; missing vertical hints at index 2; column clues add up to 4 filled cells but row clues add up to 3", invalid.Error())
+	require.ErrorAs(t, h.Validate(nil), &ErrClueTooLong{})
+	require.ErrorAs(t, h.Validate(nil), &ErrTotalsMismatch{})
+
+	require.ErrorAs(t, h.Validate(board.New(2, 2)), &ErrInvalidBoardSize{})
+}
@@ -0,0 +1,82 @@
+package hint
+
+import (
+	"nonogram/board"
+	"nonogram/line"
+	"slices"
+)
+
+// Validate checks the puzzle as a whole and returns ErrInvalidPuzzle with
+// every problem it finds, or nil. It looks for missing clues, clues mixing
+// zeros with other counts, clues that do not fit their line, different totals
+// of filled cells by rows and by columns, and known cells that contradict
+// their row or column. b may be nil when there are no known cells.
+func (t *Hints) Validate(b *board.Board) error {
+	width, height := len(t.Vertical), len(t.Horizontal)
+	problems := []error{}
+	if b != nil {
+		boardWidth, boardHeight := b.Size()
+		if boardWidth != width || boardHeight != height {
+			problems = append(problems, ErrInvalidBoardSize{
+				expected: [2]int{width, height},
+				actual:   [2]int{boardWidth, boardHeight},
+			})
+			b = nil
+		}
+	}
+
+	columns := 0
+	for x, hint := range t.Vertical {
+		problems = append(problems, validateClue(false, x, hint, height)...)
+		columns += sum(hint)
+		if b != nil && !line.Fits(b.Column(x), hint) {
+			problems = append(problems, ErrInvalid{isRow: false, index: x, line: b.Column(x), hint: hint})
+		}
+	}
+	rows := 0
+	for y, hint := range t.Horizontal {
+		problems = append(problems, validateClue(true, y, hint, width)...)
+		rows += sum(hint)
+		if b != nil && !line.Fits(b.Row(y), hint) {
+			problems = append(problems, ErrInvalid{isRow: true, index: y, line: b.Row(y), hint: hint})
+		}
+	}
+	if columns != rows {
+		problems = append(problems, ErrTotalsMismatch{columns: columns, rows: rows})
+	}
+
+	if len(problems) > 0 {
+		return ErrInvalidPuzzle{Problems: problems}
+	}
+	return nil
+}
+
+func validateClue(isRow bool, index int, hint []int, length int) []error {
+	direction := "vertical"
+	if isRow {
+		direction = "horizontal"
+	}
+	if len(hint) == 0 {
+		return []error{ErrMissingHints{direction: direction, index: index}}
+	}
+	problems := []error{}
+	if len(hint) > 1 && slices.Contains(hint, 0) {
+		problems = append(problems, ErrInvalidZero{isRow: isRow, index: index, hint: hint})
+	}
+	needed := sum(hint) + len(hint) - 1
+	if slices.Contains(hint, 0) {
+		needed = sum(hint)
+	}
+	if needed > length {
+		problems = append(problems, ErrClueTooLong{isRow: isRow, index: index, hint: hint, length: length})
+	}
+	return problems
+}
+
+func sum(hint []int) int {
+	total := 0
+	for _, v := range hint {
+		total += v
+	}
+	return total
+}
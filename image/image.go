@@ -2,9 +2,12 @@ package image
 
 import (
 	"image"
+	"image/color"
+	"image/draw"
 	"image/png"
 	"io"
 	"nonogram/board"
+	"slices"
 )
 
 const (
@@ -58,3 +61,39 @@ func Render(w io.Writer, b *board.Board) error {
 	}
 	return png.Encode(w, img)
 }
+
+var (
+	background = color.Gray{Y: 0x00}
+	cellColors = map[board.CellState]color.Color{
+		board.Empty:   color.Gray{Y: 0xff},
+		board.Filled:  color.Gray{Y: 0x33},
+		board.Crossed: color.Gray{Y: 0x66},
+	}
+	highlightColors = map[board.CellState]color.Color{
+		board.Empty:   color.Gray{Y: 0xff},
+		board.Filled:  color.RGBA{R: 0xe6, G: 0x7e, B: 0x22, A: 0xff},
+		board.Crossed: color.RGBA{R: 0xf5, G: 0xc6, B: 0x9b, A: 0xff},
+	}
+)
+
+func RenderHighlighted(w io.Writer, b *board.Board, highlighted []image.Point) error {
+	bw, bh := b.Size()
+	if bw <= 0 || bh <= 0 {
+		return ErrInvalidSize{}
+	}
+	iw := bw*scale + (bw - 1)
+	ih := bh*scale + (bh - 1)
+	img := image.NewRGBA(image.Rect(0, 0, iw, ih))
+	draw.Draw(img, img.Bounds(), image.NewUniform(background), image.Point{}, draw.Src)
+	for by := 0; by < bh; by++ {
+		for bx := 0; bx < bw; bx++ {
+			c := cellColors[b.Get(bx, by)]
+			if slices.Contains(highlighted, image.Pt(bx, by)) {
+				c = highlightColors[b.Get(bx, by)]
+			}
+			r := image.Rect(bx*(scale+1), by*(scale+1), bx*(scale+1)+scale, by*(scale+1)+scale)
+			draw.Draw(img, r, image.NewUniform(c), image.Point{}, draw.Src)
+		}
+	}
+	return png.Encode(w, img)
+}
@@ -0,0 +1,209 @@
+package line
+
+import "nonogram/board"
+
+// Solve returns a copy of cells where every cell that has the same state in
+// all placements of the clue blocks consistent with the known cells is set to
+// that state. It returns false when no placement is consistent with cells.
+func Solve(cells []board.CellState, clue []int) ([]board.CellState, bool) {
+	blocks := blocks(clue)
+	n, k := len(cells), len(blocks)
+	if n == 0 {
+		return []board.CellState{}, k == 0
+	}
+
+	crossed := crossedCounts(cells)
+	before := prefixes(cells, blocks, crossed)
+	after := suffixes(cells, blocks, crossed)
+
+	canBlank := make([]bool, n)
+	for i := 0; i < n; i++ {
+		for j := 0; j <= k; j++ {
+			if before[i+1][j] && after[i][j] {
+				canBlank[i] = true
+				break
+			}
+		}
+	}
+
+	// canFill is a difference array: every consistent placement of a block
+	// at [s, e) adds one at s and removes it at e.
+	canFill := make([]int, n+1)
+	for j, size := range blocks {
+		for s := 0; s+size <= n; s++ {
+			e := s + size
+			if crossed[e]-crossed[s] == 0 && before[s][j] && after[e][j+1] {
+				canFill[s]++
+				canFill[e]--
+			}
+		}
+	}
+
+	solved := make([]board.CellState, n)
+	placements := 0
+	for i := range cells {
+		placements += canFill[i]
+		switch {
+		case placements > 0 && canBlank[i]:
+			solved[i] = cells[i]
+		case placements > 0:
+			solved[i] = board.Filled
+		case canBlank[i]:
+			solved[i] = board.Crossed
+		default:
+			return nil, false
+		}
+	}
+	return solved, true
+}
+
+// Overlap is the simple technique of comparing the left-most and right-most
+// positions of every block. Cells covered by a block in both positions are
+// filled, and cells no block can reach are crossed. It fixes a subset of the
+// cells fixed by Solve.
+func Overlap(cells []board.CellState, clue []int) ([]board.CellState, bool) {
+	blocks := blocks(clue)
+	n, k := len(cells), len(blocks)
+	if n == 0 {
+		return []board.CellState{}, k == 0
+	}
+
+	crossed := crossedCounts(cells)
+	before := prefixes(cells, blocks, crossed)
+	after := suffixes(cells, blocks, crossed)
+
+	solved := make([]board.CellState, n)
+	copy(solved, cells)
+	reachable := make([]bool, n)
+	for j, size := range blocks {
+		first, last := -1, -1
+		for s := 0; s+size <= n; s++ {
+			e := s + size
+			if crossed[e]-crossed[s] == 0 && before[s][j] && after[e][j+1] {
+				if first < 0 {
+					first = s
+				}
+				last = s
+			}
+		}
+		if first < 0 {
+			return nil, false
+		}
+		for i := first; i < last+size; i++ {
+			reachable[i] = true
+		}
+		for i := last; i < first+size; i++ {
+			solved[i] = board.Filled
+		}
+	}
+	for i := range solved {
+		if !reachable[i] {
+			if solved[i] == board.Filled {
+				return nil, false
+			}
+			solved[i] = board.Crossed
+		}
+	}
+	return solved, true
+}
+
+// Fits reports whether at least one placement of the clue blocks is
+// consistent with the known cells.
+func Fits(cells []board.CellState, clue []int) bool {
+	blocks := blocks(clue)
+	n, k := len(cells), len(blocks)
+	crossed := crossedCounts(cells)
+	before := prefixes(cells, blocks, crossed)
+	if before[n][k] {
+		return true
+	}
+	if k == 0 {
+		return false
+	}
+	s := n - blocks[k-1]
+	return s >= 0 && crossed[n]-crossed[s] == 0 && before[s][k-1]
+}
+
+// prefixes returns a table where [i][j] tells whether cells[:i] can hold
+// exactly the first j blocks with cells[i-1] left blank (or i == 0).
+func prefixes(cells []board.CellState, blocks []int, crossed []int) [][]bool {
+	n, k := len(cells), len(blocks)
+	before := table(n+1, k+1)
+	before[0][0] = true
+	for i := 1; i <= n; i++ {
+		if cells[i-1] == board.Filled {
+			continue
+		}
+		for j := 0; j <= k; j++ {
+			if before[i-1][j] {
+				before[i][j] = true
+				continue
+			}
+			if j == 0 {
+				continue
+			}
+			s := i - 1 - blocks[j-1]
+			if s >= 0 && crossed[i-1]-crossed[s] == 0 && before[s][j-1] {
+				before[i][j] = true
+			}
+		}
+	}
+	return before
+}
+
+// suffixes returns a table where [i][j] tells whether cells[i:] can hold
+// exactly the blocks from j onwards with cells[i] left blank (or i == n).
+func suffixes(cells []board.CellState, blocks []int, crossed []int) [][]bool {
+	n, k := len(cells), len(blocks)
+	after := table(n+1, k+1)
+	after[n][k] = true
+	for i := n - 1; i >= 0; i-- {
+		if cells[i] == board.Filled {
+			continue
+		}
+		for j := k; j >= 0; j-- {
+			if after[i+1][j] {
+				after[i][j] = true
+				continue
+			}
+			if j == k {
+				continue
+			}
+			e := i + 1 + blocks[j]
+			if e <= n && crossed[e]-crossed[i+1] == 0 && after[e][j+1] {
+				after[i][j] = true
+			}
+		}
+	}
+	return after
+}
+
+func crossedCounts(cells []board.CellState) []int {
+	crossed := make([]int, len(cells)+1)
+	for i, cell := range cells {
+		crossed[i+1] = crossed[i]
+		if cell == board.Crossed {
+			crossed[i+1]++
+		}
+	}
+	return crossed
+}
+
+func blocks(clue []int) []int {
+	blocks := make([]int, 0, len(clue))
+	for _, size := range clue {
+		if size > 0 {
+			blocks = append(blocks, size)
+		}
+	}
+	return blocks
+}
+
+func table(rows, columns int) [][]bool {
+	cells := make([]bool, rows*columns)
+	t := make([][]bool, rows)
+	for i := range t {
+		t[i] = cells[i*columns : (i+1)*columns]
+	}
+	return t
+}
@@ -0,0 +1,75 @@
+package line
+
+import (
+	"nonogram/board"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func parse(s string) []board.CellState {
+	cells := make([]board.CellState, 0, len(s))
+	for _, r := range s {
+		switch r {
+		case '#':
+			cells = append(cells, board.Filled)
+		case 'X':
+			cells = append(cells, board.Crossed)
+		default:
+			cells = append(cells, board.Empty)
+		}
+	}
+	return cells
+}
+
+func TestSolve(t *testing.T) {
+	tests := []struct {
+		cells    string
+		clue     []int
+		expected string
+		ok       bool
+	}{
+		{cells: "..........", clue: []int{8}, expected: "..######..", ok: true},
+		{cells: ".....", clue: []int{0}, expected: "XXXXX", ok: true},
+		{cells: ".....", clue: []int{5}, expected: "#####", ok: true},
+		{cells: "......", clue: []int{2, 2}, expected: ".#..#.", ok: true},
+		{cells: "...#......", clue: []int{3}, expected: "X..#..XXXX", ok: true},
+		{cells: "..X..", clue: []int{3}, expected: "", ok: false},
+		{cells: "##.", clue: []int{1, 1}, expected: "", ok: false},
+		{cells: "#.#.#", clue: []int{1, 1, 1}, expected: "#X#X#", ok: true},
+		{cells: ".X...", clue: []int{2}, expected: "XX.#.", ok: true},
+	}
+	for _, test := range tests {
+		solved, ok := Solve(parse(test.cells), test.clue)
+		require.Equal(t, test.ok, ok, "%s %v", test.cells, test.clue)
+		require.Equal(t, test.ok, Fits(parse(test.cells), test.clue), "%s %v", test.cells, test.clue)
+		if ok {
+			require.Equal(t, parse(test.expected), solved, "%s %v", test.cells, test.clue)
+		}
+	}
+}
+
+func TestOverlap(t *testing.T) {
+	tests := []struct {
+		cells    string
+		clue     []int
+		expected string
+		ok       bool
+	}{
+		{cells: "..........", clue: []int{8}, expected: "..######..", ok: true},
+		{cells: ".....", clue: []int{0}, expected: "XXXXX", ok: true},
+		{cells: "...#......", clue: []int{3}, expected: "X..#..XXXX", ok: true},
+		{cells: "..#..", clue: []int{1, 1}, expected: "..#..", ok: true},
+		{cells: "..X..", clue: []int{3}, expected: "", ok: false},
+	}
+	for _, test := range tests {
+		solved, ok := Overlap(parse(test.cells), test.clue)
+		require.Equal(t, test.ok, ok, "%s %v", test.cells, test.clue)
+		if ok {
+			require.Equal(t, parse(test.expected), solved, "%s %v", test.cells, test.clue)
+		}
+	}
+
+	solved, _ := Solve(parse("..#.."), []int{1, 1})
+	require.Equal(t, parse(".X#X."), solved)
+}
@@ -5,14 +5,18 @@ import (
 	"context"
 	_ "embed"
 	"errors"
+	"flag"
 	"fmt"
+	stdimage "image"
 	_ "image/png"
 	"io"
 	"log"
 	"net"
 	"net/http"
 	"nonogram/board"
+	_ "nonogram/cnf"
 	"nonogram/encoding"
+	"nonogram/generator"
 	"nonogram/hint"
 	"nonogram/image"
 	"nonogram/screen"
@@ -21,6 +25,8 @@ import (
 	"os/signal"
 	"regexp"
 	"runtime"
+	"strconv"
+	"strings"
 	"sync"
 	"time"
 
@@ -33,55 +39,133 @@ import (
 var indexHTML []byte
 
 var (
-	ErrSolverTimeLimit = errors.New("solver time limit exceeded")
-	ErrNoSolution      = errors.New("no solution found")
-
-	mutex sync.Mutex
+	ErrSolverTimeLimit   = errors.New("solver time limit exceeded")
+	ErrNoSolution        = errors.New("no solution found")
+	ErrManySolutions     = errors.New("more than one solution found")
+	ErrUnfinished        = errors.New("solver could not finish the puzzle")
+	ErrInvalidPuzzleSize = errors.New("puzzle size must be between 1x1 and 30x30")
+
+	mutex      sync.Mutex
+	solverName = solver.DefaultSolver
+	decoder    = &screen.Decoder{}
 )
 
-func decodeFromScreenshort(ctx context.Context, r io.Reader) (b *board.Board, h *hint.Hints, err error) {
-	return screen.Decode(r)
+func decodeFromScreenshort(ctx context.Context, r io.Reader) (b *board.Board, h *hint.Hints, report *screen.Report, err error) {
+	return decoder.Decode(r)
 }
 
 func decodeFromText(ctx context.Context, r io.Reader) (b *board.Board, h *hint.Hints, err error) {
 	return encoding.Decode(r)
 }
 
-func solve(ctx context.Context, b *board.Board, h *hint.Hints) (start time.Time, count uint64, decodedPNG, solvedPNG *bytes.Buffer, err error) {
+func solve(ctx context.Context, name string, b *board.Board, h *hint.Hints) (result solver.Result, decodedPNG, solvedPNG *bytes.Buffer, err error) {
 	ctx, cancel := context.WithTimeoutCause(ctx, time.Minute, ErrSolverTimeLimit)
 	defer cancel()
 
+	s, err := solver.Lookup(name)
+	if err != nil {
+		return solver.Result{}, nil, nil, err
+	}
+
 	decodedPNG = new(bytes.Buffer)
 	err = image.Render(decodedPNG, b)
 	if err != nil {
-		return time.Time{}, 0, nil, nil, fmt.Errorf("failed to render image: %w", err)
+		return solver.Result{}, nil, nil, fmt.Errorf("failed to render image: %w", err)
 	}
 
-	solved, stats, err := solver.Solve(ctx, b, h)
+	result, err = s.Solve(ctx, b, h)
 	if cause := context.Cause(ctx); cause != nil {
-		return time.Time{}, 0, decodedPNG, nil, cause
+		err = cause
 	}
-	if err != nil {
-		return time.Time{}, 0, decodedPNG, nil, err
+	if err != nil && !errors.Is(err, ErrSolverTimeLimit) {
+		return solver.Result{}, decodedPNG, nil, err
+	}
+	if err == nil {
+		switch result.Status {
+		case solver.NoSolution:
+			return solver.Result{}, decodedPNG, nil, ErrNoSolution
+		case solver.Ambiguous:
+			return solver.Result{}, decodedPNG, nil, ErrManySolutions
+		case solver.Partial:
+			err = ErrUnfinished
+		}
 	}
-	if solved == nil {
-		return time.Time{}, 0, decodedPNG, nil, ErrNoSolution
+	if err != nil && (result.Board == nil || result.Board.Count(board.Empty) >= b.Count(board.Empty)) {
+		return solver.Result{}, decodedPNG, nil, err
 	}
 
 	solvedPNG = new(bytes.Buffer)
-	err = image.Render(solvedPNG, solved)
+	renderErr := image.Render(solvedPNG, result.Board)
+	if renderErr != nil {
+		return solver.Result{}, decodedPNG, nil, fmt.Errorf("failed to render image: %w", renderErr)
+	}
+
+	return result, decodedPNG, solvedPNG, err
+}
+
+func generate(ctx context.Context, opts generator.Options) (*generator.Puzzle, error) {
+	if opts.Width <= 0 || opts.Height <= 0 || opts.Width > maxPuzzleSize || opts.Height > maxPuzzleSize {
+		return nil, ErrInvalidPuzzleSize
+	}
+	ctx, cancel := context.WithTimeout(ctx, time.Minute)
+	defer cancel()
+	return generator.Generate(ctx, opts)
+}
+
+// puzzleText returns the hints in the text format read by decodeFromText.
+func puzzleText(h *hint.Hints) (string, error) {
+	var text strings.Builder
+	if err := encoding.Encode(&text, board.New(len(h.Vertical), len(h.Horizontal)), h); err != nil {
+		return "", err
+	}
+	return text.String(), nil
+}
+
+// rate returns the difficulty of a solved puzzle, or nil when rating it fails
+// or takes too long.
+func rate(ctx context.Context, b *board.Board, h *hint.Hints) *solver.Difficulty {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	difficulty, err := solver.Rate(ctx, b, h)
+	if err != nil {
+		return nil
+	}
+	return &difficulty
+}
+
+func nextHint(b *board.Board, h *hint.Hints) (step solver.Step, hintPNG *bytes.Buffer, err error) {
+	step, err = solver.Next(b, h)
 	if err != nil {
-		return time.Time{}, 0, decodedPNG, nil, fmt.Errorf("failed to render image: %w", err)
+		return solver.Step{}, nil, err
 	}
 
-	return stats.Start, stats.Count, decodedPNG, solvedPNG, nil
+	next := b.Clone()
+	step.Apply(next)
+	highlighted := make([]stdimage.Point, len(step.Cells))
+	for i, cell := range step.Cells {
+		highlighted[i] = stdimage.Pt(cell.X, cell.Y)
+	}
+
+	hintPNG = new(bytes.Buffer)
+	err = image.RenderHighlighted(hintPNG, next, highlighted)
+	if err != nil {
+		return solver.Step{}, nil, fmt.Errorf("failed to render image: %w", err)
+	}
+
+	return step, hintPNG, nil
 }
 
-func handleScreenshot(ctx context.Context, b *bot.Bot, update *models.Update) (*board.Board, *hint.Hints) {
-	if update.Message.Document == nil || update.Message.Document.MimeType != "image/png" {
+// handleDocument reads a PNG screenshot, or a puzzle sent back as the .txt
+// file attached to a decoded screenshot.
+func handleDocument(ctx context.Context, b *bot.Bot, update *models.Update) (*board.Board, *hint.Hints) {
+	chatID := update.Message.Chat.ID
+	mimeType := update.Message.Document.MimeType
+	isText := strings.HasPrefix(mimeType, "text/plain")
+	if mimeType != "image/png" && !isText {
 		b.SendMessage(ctx, &bot.SendMessageParams{
-			ChatID: update.Message.Chat.ID,
-			Text:   "Please send a PNG screenshot of the Nonogram game. You have to send it as a file/document so that Telegram doesn't convert it to a JPG.",
+			ChatID: chatID,
+			Text:   "Please send a PNG screenshot of the Nonogram game, or the puzzle as a .txt file. You have to send screenshots as a file/document so that Telegram doesn't convert them to a JPG.",
 		})
 		return nil, nil
 	}
@@ -91,7 +175,7 @@ func handleScreenshot(ctx context.Context, b *bot.Bot, update *models.Update) (*
 	})
 	if err != nil {
 		b.SendMessage(ctx, &bot.SendMessageParams{
-			ChatID:    update.Message.Chat.ID,
+			ChatID:    chatID,
 			Text:      fmt.Sprintf("Failed to get file info:\n```%v```", err),
 			ParseMode: models.ParseModeMarkdown,
 		})
@@ -103,7 +187,7 @@ func handleScreenshot(ctx context.Context, b *bot.Bot, update *models.Update) (*
 	res, err := http.Get(url)
 	if err != nil {
 		b.SendMessage(ctx, &bot.SendMessageParams{
-			ChatID:    update.Message.Chat.ID,
+			ChatID:    chatID,
 			Text:      fmt.Sprintf("Failed to download file:\n```%v```", err),
 			ParseMode: models.ParseModeMarkdown,
 		})
@@ -113,41 +197,211 @@ func handleScreenshot(ctx context.Context, b *bot.Bot, update *models.Update) (*
 
 	if res.StatusCode != http.StatusOK {
 		b.SendMessage(ctx, &bot.SendMessageParams{
-			ChatID:    update.Message.Chat.ID,
-			Text:      fmt.Sprintf("Failed to download file:\n```%v```", err),
+			ChatID:    chatID,
+			Text:      fmt.Sprintf("Failed to download file:\n```%v```", res.Status),
 			ParseMode: models.ParseModeMarkdown,
 		})
 		return nil, nil
 	}
 
-	bd, h, err := decodeFromScreenshort(ctx, res.Body)
+	if isText {
+		text, err := io.ReadAll(res.Body)
+		if err != nil {
+			b.SendMessage(ctx, &bot.SendMessageParams{
+				ChatID:    chatID,
+				Text:      fmt.Sprintf("Failed to download file:\n```%v```", err),
+				ParseMode: models.ParseModeMarkdown,
+			})
+			return nil, nil
+		}
+		bd, h := decodeMessage(ctx, b, chatID, strings.TrimSpace(string(text)))
+		if bd != nil {
+			delete(pending, chatID)
+		}
+		return bd, h
+	}
+
+	bd, h, report, err := decodeFromScreenshort(ctx, res.Body)
+	if report != nil && len(report.Uncertain) > 0 {
+		pendingID++
+		pending[chatID] = &pendingPuzzle{id: pendingID, board: bd, hints: h, clues: report.Uncertain}
+		askClue(ctx, b, chatID, pending[chatID])
+		return nil, nil
+	}
 	if err != nil {
 		b.SendMessage(ctx, &bot.SendMessageParams{
-			ChatID:    update.Message.Chat.ID,
+			ChatID:    chatID,
 			Text:      fmt.Sprintf("Failed to decode screenshot:\n```%v```", err),
 			ParseMode: models.ParseModeMarkdown,
 		})
 		return nil, nil
 	}
 
+	delete(pending, chatID)
+	sendDecoded(ctx, b, chatID, bd, h)
 	return bd, h
 }
 
+func sendDecoded(ctx context.Context, b *bot.Bot, chatID int64, bd *board.Board, h *hint.Hints) {
+	decoded := new(bytes.Buffer)
+	if err := encoding.Encode(decoded, bd, h); err == nil {
+		b.SendDocument(ctx, &bot.SendDocumentParams{
+			ChatID:  chatID,
+			Caption: "This is how the screenshot was read. If a clue is wrong, fix it and send the text back.",
+			Document: &models.InputFileUpload{
+				Filename: "puzzle.txt",
+				Data:     decoded,
+			},
+		})
+	}
+}
+
+// pendingPuzzle is a screenshot read with uncertain clues, waiting for the
+// user to pick the right reading of every one of them.
+type pendingPuzzle struct {
+	id    int
+	board *board.Board
+	hints *hint.Hints
+	clues []screen.Clue
+}
+
+// pending holds the puzzle waiting for answers in every chat. Like the rest
+// of the bot state it is only used while holding mutex.
+var pending = map[int64]*pendingPuzzle{}
+
+// pendingID numbers the pending puzzles, so buttons of an earlier screenshot
+// cannot answer for a later one.
+var pendingID int
+
+func clueLine(h *hint.Hints, clue screen.Clue) []int {
+	if clue.IsRow {
+		return h.Horizontal[clue.Index]
+	}
+	return h.Vertical[clue.Index]
+}
+
+// askClue asks about the first uncertain clue of the pending puzzle, with a
+// button for each reading.
+func askClue(ctx context.Context, b *bot.Bot, chatID int64, p *pendingPuzzle) {
+	clue := p.clues[0]
+	direction := "Column"
+	if clue.IsRow {
+		direction = "Row"
+	}
+	text := fmt.Sprintf("%s %d: is this %d or %d?", direction, clue.Index+1, clue.Value, clue.RunnerUp)
+	if len(clueLine(p.hints, clue)) > 1 {
+		text = fmt.Sprintf("%s %d, number %d: is this %d or %d?", direction, clue.Index+1, clue.Position+1, clue.Value, clue.RunnerUp)
+	}
+	b.SendMessage(ctx, &bot.SendMessageParams{
+		ChatID: chatID,
+		Text:   text,
+		ReplyMarkup: &models.InlineKeyboardMarkup{
+			InlineKeyboard: [][]models.InlineKeyboardButton{{
+				{Text: strconv.Itoa(clue.Value), CallbackData: clueData(p.id, clue, clue.Value)},
+				{Text: strconv.Itoa(clue.RunnerUp), CallbackData: clueData(p.id, clue, clue.RunnerUp)},
+			}},
+		},
+	})
+}
+
+// clueData is the callback data of the button that answers clue of puzzle id
+// with value. It names the puzzle and the clue, so a late tap on an answered
+// question is not taken for the next one.
+func clueData(id int, clue screen.Clue, value int) string {
+	return fmt.Sprintf("%s%d:%t:%d:%d:%d", clueCallback, id, clue.IsRow, clue.Index, clue.Position, value)
+}
+
+// handleClue sets the uncertain clue asked about last to the reading picked by
+// the user, and solves the puzzle once every clue is settled. Answers to any
+// other question are ignored.
+func handleClue(ctx context.Context, b *bot.Bot, query *models.CallbackQuery, chatID int64) {
+	var id, index, position, value int
+	var isRow bool
+	_, err := fmt.Sscanf(query.Data, clueCallback+"%d:%t:%d:%d:%d", &id, &isRow, &index, &position, &value)
+	p := pending[chatID]
+	if err != nil || p == nil || id != p.id {
+		b.AnswerCallbackQuery(ctx, &bot.AnswerCallbackQueryParams{
+			CallbackQueryID: query.ID,
+			Text:            "This screenshot is no longer waiting for answers, please send it again.",
+		})
+		return
+	}
+	clue := p.clues[0]
+	if isRow != clue.IsRow || index != clue.Index || position != clue.Position || (value != clue.Value && value != clue.RunnerUp) {
+		b.AnswerCallbackQuery(ctx, &bot.AnswerCallbackQueryParams{
+			CallbackQueryID: query.ID,
+			Text:            "This question was already answered.",
+		})
+		return
+	}
+	b.AnswerCallbackQuery(ctx, &bot.AnswerCallbackQueryParams{CallbackQueryID: query.ID})
+	clueLine(p.hints, clue)[clue.Position] = value
+	p.clues = p.clues[1:]
+	if len(p.clues) > 0 {
+		askClue(ctx, b, chatID, p)
+		return
+	}
+
+	bd, h := p.board, p.hints
+	delete(pending, chatID)
+	if err := h.Validate(bd); err != nil {
+		b.SendMessage(ctx, &bot.SendMessageParams{
+			ChatID:    chatID,
+			Text:      fmt.Sprintf("Failed to decode screenshot:\n```%v```", err),
+			ParseMode: models.ParseModeMarkdown,
+		})
+		return
+	}
+	sendDecoded(ctx, b, chatID, bd, h)
+	handleSolve(ctx, b, chatID, bd, h)
+}
+
 var firstLineRegexp = regexp.MustCompile(`(?m)^\d+ \d+$`)
+var puzzleSizeRegexp = regexp.MustCompile(`^(\d+)x(\d+)$`)
 var lastBoardSpecText string
 
+const (
+	hintCommand   = "/hint"
+	solverCommand = "/solver"
+	newCommand    = "/new"
+
+	clueCallback = "clue:"
+
+	maxPuzzleSize = 30
+)
+
 func handleText(ctx context.Context, b *bot.Bot, update *models.Update) (*board.Board, *hint.Hints) {
+	message := strings.TrimSpace(strings.TrimPrefix(update.Message.Text, hintCommand))
+	bd, h := decodeMessage(ctx, b, update.Message.Chat.ID, message)
+	if bd != nil {
+		delete(pending, update.Message.Chat.ID)
+	}
+	return bd, h
+}
+
+// decodeMessage reads a puzzle sent as text, either a whole puzzle or known
+// cells of the last one.
+func decodeMessage(ctx context.Context, b *bot.Bot, chatID int64, message string) (*board.Board, *hint.Hints) {
 	var text string
-	if firstLineRegexp.MatchString(update.Message.Text) {
-		lastBoardSpecText = update.Message.Text
-		text = update.Message.Text
+	// offset is the number of lines of the previous puzzle in front of the
+	// message, so errors point at the line the user sent.
+	offset := 0
+	if firstLineRegexp.MatchString(message) {
+		lastBoardSpecText = message
+		text = message
 	} else {
-		text = lastBoardSpecText + "\n" + update.Message.Text
+		text = lastBoardSpecText + "\n" + message
+		offset = strings.Count(lastBoardSpecText, "\n") + 1
 	}
 	bd, h, err := decodeFromText(ctx, bytes.NewBufferString(text))
+	var parseErr encoding.ErrParse
+	if errors.As(err, &parseErr) && parseErr.Line > offset {
+		parseErr.Line -= offset
+		err = parseErr
+	}
 	if err != nil {
 		b.SendMessage(ctx, &bot.SendMessageParams{
-			ChatID:    update.Message.Chat.ID,
+			ChatID:    chatID,
 			Text:      fmt.Sprintf("Failed to decode text:\n```%v```", err),
 			ParseMode: models.ParseModeMarkdown,
 		})
@@ -156,44 +410,169 @@ func handleText(ctx context.Context, b *bot.Bot, update *models.Update) (*board.
 	return bd, h
 }
 
+func handleHint(ctx context.Context, b *bot.Bot, update *models.Update, bd *board.Board, h *hint.Hints) {
+	step, hintPNG, err := nextHint(bd, h)
+	if errors.As(err, &solver.ErrNoDeduction{}) {
+		b.SendMessage(ctx, &bot.SendMessageParams{
+			ChatID: update.Message.Chat.ID,
+			Text:   "No cell can be deduced from a single row or column right now. You will have to try a cell and see where it leads.",
+		})
+		return
+	}
+	if err != nil {
+		b.SendMessage(ctx, &bot.SendMessageParams{
+			ChatID:    update.Message.Chat.ID,
+			Text:      fmt.Sprintf("Failed to find a hint:\n```%v```", err),
+			ParseMode: models.ParseModeMarkdown,
+		})
+		return
+	}
+	b.SendPhoto(ctx, &bot.SendPhotoParams{
+		ChatID:  update.Message.Chat.ID,
+		Caption: fmt.Sprintf("Look at %s %d, it forces %d more cell(s).", step.Direction, step.Index+1, len(step.Cells)),
+		Photo: &models.InputFileUpload{
+			Filename: "hint.png",
+			Data:     hintPNG,
+		},
+	})
+}
+
+func handleSolver(ctx context.Context, b *bot.Bot, update *models.Update) {
+	name := strings.TrimSpace(strings.TrimPrefix(update.Message.Text, solverCommand))
+	if name == "" {
+		b.SendMessage(ctx, &bot.SendMessageParams{
+			ChatID: update.Message.Chat.ID,
+			Text:   fmt.Sprintf("Using the %s solver. Available solvers: %s.", solverName, strings.Join(solver.Names(), ", ")),
+		})
+		return
+	}
+	if _, err := solver.Lookup(name); err != nil {
+		b.SendMessage(ctx, &bot.SendMessageParams{
+			ChatID: update.Message.Chat.ID,
+			Text:   fmt.Sprintf("Unknown solver %q. Available solvers: %s.", name, strings.Join(solver.Names(), ", ")),
+		})
+		return
+	}
+	solverName = name
+	b.SendMessage(ctx, &bot.SendMessageParams{
+		ChatID: update.Message.Chat.ID,
+		Text:   fmt.Sprintf("Using the %s solver.", solverName),
+	})
+}
+
+func handleNew(ctx context.Context, b *bot.Bot, update *models.Update) {
+	size := strings.TrimSpace(strings.TrimPrefix(update.Message.Text, newCommand))
+	if size == "" {
+		size = "15x15"
+	}
+	match := puzzleSizeRegexp.FindStringSubmatch(size)
+	if match == nil {
+		b.SendMessage(ctx, &bot.SendMessageParams{
+			ChatID: update.Message.Chat.ID,
+			Text:   fmt.Sprintf("Use %s WIDTHxHEIGHT, for example %s 15x15.", newCommand, newCommand),
+		})
+		return
+	}
+	width, _ := strconv.Atoi(match[1])
+	height, _ := strconv.Atoi(match[2])
+	puzzle, err := generate(ctx, generator.Options{Width: width, Height: height})
+	if err != nil {
+		b.SendMessage(ctx, &bot.SendMessageParams{
+			ChatID:    update.Message.Chat.ID,
+			Text:      fmt.Sprintf("Failed to create a Nonogram:\n```%v```", err),
+			ParseMode: models.ParseModeMarkdown,
+		})
+		return
+	}
+	text, err := puzzleText(puzzle.Hints)
+	if err != nil {
+		b.SendMessage(ctx, &bot.SendMessageParams{
+			ChatID:    update.Message.Chat.ID,
+			Text:      fmt.Sprintf("Failed to create a Nonogram:\n```%v```", err),
+			ParseMode: models.ParseModeMarkdown,
+		})
+		return
+	}
+	delete(pending, update.Message.Chat.ID)
+	lastBoardSpecText = text
+	b.SendMessage(ctx, &bot.SendMessageParams{
+		ChatID:    update.Message.Chat.ID,
+		Text:      fmt.Sprintf("```\n%s```\nDifficulty: %s. Send known cells as \"x y v\" lines to check your progress, or %s for a hint.", lastBoardSpecText, puzzle.Difficulty, hintCommand),
+		ParseMode: models.ParseModeMarkdown,
+	})
+}
+
 func handler(ctx context.Context, b *bot.Bot, update *models.Update) {
 	mutex.Lock()
 	defer mutex.Unlock()
 
+	var chatID int64
+	switch {
+	case update.Message != nil:
+		chatID = update.Message.Chat.ID
+	case update.CallbackQuery != nil && update.CallbackQuery.Message.Message != nil:
+		chatID = update.CallbackQuery.Message.Message.Chat.ID
+	default:
+		return
+	}
+
 	defer func() {
 		if err := recover(); err != nil {
 			b.SendMessage(ctx, &bot.SendMessageParams{
-				ChatID:    update.Message.Chat.ID,
+				ChatID:    chatID,
 				Text:      fmt.Sprintf("An error occurred:\n```%v```", err),
 				ParseMode: models.ParseModeMarkdown,
 			})
 		}
 	}()
 
-	if update.Message == nil {
-		return
-	}
-	if update.Message.Chat.ID != 70260207 {
+	if chatID != 70260207 {
 		b.SendMessage(ctx, &bot.SendMessageParams{
-			ChatID: update.Message.Chat.ID,
+			ChatID: chatID,
 			Text:   "You are not authorized to use this bot.",
 		})
 		return
 	}
 
+	if update.CallbackQuery != nil {
+		handleClue(ctx, b, update.CallbackQuery, chatID)
+		return
+	}
+
+	if strings.HasPrefix(update.Message.Text, solverCommand) {
+		handleSolver(ctx, b, update)
+		return
+	}
+	if strings.HasPrefix(update.Message.Text, newCommand) {
+		handleNew(ctx, b, update)
+		return
+	}
+
 	var bd *board.Board
 	var h *hint.Hints
 	if update.Message.Document != nil {
-		bd, h = handleScreenshot(ctx, b, update)
+		bd, h = handleDocument(ctx, b, update)
 	} else if update.Message.Text != "" {
 		bd, h = handleText(ctx, b, update)
 	}
+	if bd == nil || h == nil {
+		return
+	}
 
+	if strings.HasPrefix(update.Message.Text, hintCommand) || strings.HasPrefix(update.Message.Caption, hintCommand) {
+		handleHint(ctx, b, update, bd, h)
+		return
+	}
+
+	handleSolve(ctx, b, chatID, bd, h)
+}
+
+func handleSolve(ctx context.Context, b *bot.Bot, chatID int64, bd *board.Board, h *hint.Hints) {
 	actionCtx, actionCtxCancel := context.WithCancel(ctx)
 	defer actionCtxCancel()
 	go func() {
 		b.SendChatAction(ctx, &bot.SendChatActionParams{
-			ChatID: update.Message.Chat.ID,
+			ChatID: chatID,
 			Action: models.ChatActionTyping,
 		})
 		t := time.NewTicker(4 * time.Second)
@@ -203,7 +582,7 @@ func handler(ctx context.Context, b *bot.Bot, update *models.Update) {
 				return
 			case <-t.C:
 				b.SendChatAction(ctx, &bot.SendChatActionParams{
-					ChatID: update.Message.Chat.ID,
+					ChatID: chatID,
 					Action: models.ChatActionTyping,
 				})
 			}
@@ -211,41 +590,94 @@ func handler(ctx context.Context, b *bot.Bot, update *models.Update) {
 	}()
 
 	runtime.Gosched()
-	start, count, _, solved, err := solve(ctx, bd, h)
+	result, _, solved, err := solve(ctx, solverName, bd, h)
 	actionCtxCancel()
 	runtime.Gosched()
 
+	if errors.Is(err, ErrSolverTimeLimit) && solved != nil {
+		b.SendPhoto(ctx, &bot.SendPhotoParams{
+			ChatID:  chatID,
+			Caption: "It took too long to solve the Nonogram. These cells are certain, play from here and try again.",
+			Photo: &models.InputFileUpload{
+				Filename: "proven.png",
+				Data:     solved,
+			},
+		})
+		return
+	}
+	if errors.Is(err, ErrUnfinished) && solved != nil {
+		b.SendPhoto(ctx, &bot.SendPhotoParams{
+			ChatID:  chatID,
+			Caption: fmt.Sprintf("The %s solver could not finish the Nonogram. These cells are certain, try another solver with %s.", solverName, solverCommand),
+			Photo: &models.InputFileUpload{
+				Filename: "proven.png",
+				Data:     solved,
+			},
+		})
+		return
+	}
+	if errors.Is(err, ErrUnfinished) {
+		b.SendMessage(ctx, &bot.SendMessageParams{
+			ChatID: chatID,
+			Text:   fmt.Sprintf("The %s solver could not deduce any cell of the Nonogram. Try another solver with %s.", solverName, solverCommand),
+		})
+		return
+	}
 	if errors.Is(err, ErrSolverTimeLimit) {
 		b.SendMessage(ctx, &bot.SendMessageParams{
-			ChatID: update.Message.Chat.ID,
+			ChatID: chatID,
 			Text:   "It took too long to solve the Nonogram. Please play a little more, figure out some more of the puzzle, and try again.",
 		})
 		return
 	}
 	if errors.Is(err, ErrNoSolution) {
 		b.SendMessage(ctx, &bot.SendMessageParams{
-			ChatID: update.Message.Chat.ID,
+			ChatID: chatID,
 			Text:   "No solution found for the Nonogram. Please try again.",
 		})
 		return
 	}
+	if errors.Is(err, ErrManySolutions) {
+		b.SendMessage(ctx, &bot.SendMessageParams{
+			ChatID: chatID,
+			Text:   "This Nonogram has 2 or more solutions, one of the clues was probably misread. Please check the clues and try again.",
+		})
+		return
+	}
 	if err != nil {
 		b.SendMessage(ctx, &bot.SendMessageParams{
-			ChatID:    update.Message.Chat.ID,
+			ChatID:    chatID,
 			Text:      fmt.Sprintf("Failed to solve Nonogram:\n```%v```", err),
 			ParseMode: models.ParseModeMarkdown,
 		})
 		return
 	}
-	took := time.Since(start)
-	b.SendPhoto(ctx, &bot.SendPhotoParams{
-		ChatID:  update.Message.Chat.ID,
-		Caption: fmt.Sprintf("Solved in %s after checking %d boards.", took, count),
+	caption := fmt.Sprintf("Solved in %s after checking %d boards.", result.Took, result.Stats.Count)
+	message, err := b.SendPhoto(ctx, &bot.SendPhotoParams{
+		ChatID:  chatID,
+		Caption: caption,
 		Photo: &models.InputFileUpload{
 			Filename: "solved.png",
 			Data:     solved,
 		},
 	})
+	if err != nil {
+		return
+	}
+
+	// Rating can take a while, so it runs after the handler releases the
+	// mutex and the difficulty is added to the caption once it is known.
+	go func() {
+		difficulty := rate(ctx, bd, h)
+		if difficulty == nil {
+			return
+		}
+		b.EditMessageCaption(ctx, &bot.EditMessageCaptionParams{
+			ChatID:    chatID,
+			MessageID: message.ID,
+			Caption:   caption + fmt.Sprintf(" Difficulty: %s.", difficulty),
+		})
+	}()
 }
 
 func runTGBot(ctx context.Context) error {
@@ -263,6 +695,65 @@ func runTGBot(ctx context.Context) error {
 	return nil
 }
 
+type puzzleRequest struct {
+	Puzzle string `json:"puzzle"`
+}
+
+// solveRequest takes the puzzle either as text in Puzzle or as JSON in Hints,
+// with the known cells in Board.
+type solveRequest struct {
+	Puzzle string       `json:"puzzle"`
+	Hints  *hint.Hints  `json:"hints,omitempty"`
+	Board  *board.Board `json:"board,omitempty"`
+	Solver string       `json:"solver"`
+}
+
+func (t solveRequest) decode(ctx context.Context) (*board.Board, *hint.Hints, error) {
+	if t.Hints == nil {
+		return decodeFromText(ctx, strings.NewReader(t.Puzzle))
+	}
+	b := t.Board
+	if b == nil {
+		b = board.New(len(t.Hints.Vertical), len(t.Hints.Horizontal))
+	}
+	if b == nil {
+		return nil, nil, encoding.ErrInvalidSize{}
+	}
+	if err := t.Hints.Validate(b); err != nil {
+		return nil, nil, err
+	}
+	return b, t.Hints, nil
+}
+
+type solveResponse struct {
+	Status     string             `json:"status"`
+	Took       string             `json:"took,omitempty"`
+	Count      uint64             `json:"count"`
+	Board      *board.Board       `json:"board,omitempty"`
+	Difficulty *solver.Difficulty `json:"difficulty,omitempty"`
+	Image      []byte             `json:"image,omitempty"`
+}
+
+type generateRequest struct {
+	Width    int     `json:"width"`
+	Height   int     `json:"height"`
+	Density  float64 `json:"density"`
+	MinScore int     `json:"minScore"`
+	MaxScore int     `json:"maxScore"`
+}
+
+type generateResponse struct {
+	Puzzle     string            `json:"puzzle"`
+	Columns    [][]int           `json:"columns"`
+	Rows       [][]int           `json:"rows"`
+	Difficulty solver.Difficulty `json:"difficulty"`
+}
+
+type hintResponse struct {
+	Step  solver.Step `json:"step"`
+	Image []byte      `json:"image"`
+}
+
 func runWebServer(ctx context.Context) error {
 	listener, err := net.Listen("tcp", ":9999")
 	if err != nil {
@@ -283,6 +774,96 @@ func runWebServer(ctx context.Context) error {
 		return fuego.HTML(indexHTML), nil
 	})
 
+	fuego.Post(s, "/api/solve", func(c fuego.ContextWithBody[solveRequest]) (solveResponse, error) {
+		body, err := c.Body()
+		if err != nil {
+			return solveResponse{}, err
+		}
+		name := body.Solver
+		if name == "" {
+			mutex.Lock()
+			name = solverName
+			mutex.Unlock()
+		}
+		if _, err := solver.Lookup(name); err != nil {
+			return solveResponse{}, fuego.BadRequestError{Detail: err.Error(), Err: err}
+		}
+		b, h, err := body.decode(c.Context())
+		if err != nil {
+			return solveResponse{}, fuego.BadRequestError{Detail: err.Error(), Err: err}
+		}
+		result, _, solved, err := solve(c.Context(), name, b, h)
+		response := solveResponse{Status: string(solver.Solved), Count: result.Stats.Count}
+		switch {
+		case errors.Is(err, ErrNoSolution):
+			response.Status = string(solver.NoSolution)
+		case errors.Is(err, ErrManySolutions):
+			response.Status = string(solver.Ambiguous)
+		case errors.Is(err, ErrUnfinished), errors.Is(err, ErrSolverTimeLimit):
+			response.Status = string(solver.Partial)
+		case err != nil:
+			return solveResponse{}, err
+		}
+		if solved != nil {
+			response.Took = result.Took.String()
+			response.Board = result.Board
+			response.Image = solved.Bytes()
+		}
+		if err == nil {
+			response.Difficulty = rate(c.Context(), b, h)
+		}
+		return response, nil
+	})
+
+	fuego.Post(s, "/api/hint", func(c fuego.ContextWithBody[puzzleRequest]) (hintResponse, error) {
+		body, err := c.Body()
+		if err != nil {
+			return hintResponse{}, err
+		}
+		b, h, err := decodeFromText(c.Context(), strings.NewReader(body.Puzzle))
+		if err != nil {
+			return hintResponse{}, fuego.BadRequestError{Detail: err.Error(), Err: err}
+		}
+		step, hintPNG, err := nextHint(b, h)
+		if errors.As(err, &solver.ErrNoDeduction{}) || errors.As(err, &hint.ErrInvalid{}) {
+			return hintResponse{}, fuego.BadRequestError{Detail: err.Error(), Err: err}
+		}
+		if err != nil {
+			return hintResponse{}, err
+		}
+		return hintResponse{Step: step, Image: hintPNG.Bytes()}, nil
+	})
+
+	fuego.Post(s, "/api/generate", func(c fuego.ContextWithBody[generateRequest]) (generateResponse, error) {
+		body, err := c.Body()
+		if err != nil {
+			return generateResponse{}, err
+		}
+		puzzle, err := generate(c.Context(), generator.Options{
+			Width:    body.Width,
+			Height:   body.Height,
+			Density:  body.Density,
+			MinScore: body.MinScore,
+			MaxScore: body.MaxScore,
+		})
+		if errors.Is(err, ErrInvalidPuzzleSize) || errors.As(err, &generator.ErrInvalidDensity{}) || errors.As(err, &generator.ErrNoPuzzle{}) {
+			return generateResponse{}, fuego.BadRequestError{Detail: err.Error(), Err: err}
+		}
+		if err != nil {
+			return generateResponse{}, err
+		}
+		text, err := puzzleText(puzzle.Hints)
+		if err != nil {
+			return generateResponse{}, err
+		}
+		return generateResponse{
+			Puzzle:     text,
+			Columns:    puzzle.Hints.Vertical,
+			Rows:       puzzle.Hints.Horizontal,
+			Difficulty: puzzle.Difficulty,
+		}, nil
+	})
+
 	return s.Run()
 }
 
@@ -317,6 +898,27 @@ func run() error {
 }
 
 func main() {
+	flag.StringVar(&solverName, "solver", solver.DefaultSolver, "solver strategy, one of "+strings.Join(solver.Names(), ", "))
+	templatesDir := flag.String("templates", "", "directory of digit templates named {size}_{digit}.png, the embedded ones when empty")
+	flag.Parse()
+	if _, err := solver.Lookup(solverName); err != nil {
+		log.Fatal(err)
+	}
+
+	templates, err := screen.DefaultTemplates()
+	if *templatesDir != "" {
+		templates, err = screen.LoadTemplates(os.DirFS(*templatesDir))
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+	for _, size := range []int{10, 15} {
+		if _, err := templates.Digits(size); err != nil {
+			log.Fatal(err)
+		}
+	}
+	decoder.Templates = templates
+
 	if err := run(); err != nil {
 		log.Fatal(err)
 	}
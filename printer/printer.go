@@ -3,6 +3,8 @@ package printer
 import (
 	"io"
 	"nonogram/board"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"golang.org/x/text/language"
@@ -15,12 +17,12 @@ func init() {
 	mp = message.NewPrinter(language.English)
 }
 
-func PrintBoard(w io.Writer, b *board.Board, count *uint64, started *time.Time) {
+func PrintBoard(w io.Writer, b *board.Board, count *atomic.Uint64, started *time.Time) {
 	switch {
 	case count != nil && started != nil:
-		mp.Fprintf(w, "analized %d boards in %s\n", *count, time.Since(*started).String())
+		mp.Fprintf(w, "analized %d boards in %s\n", count.Load(), time.Since(*started).String())
 	case count != nil && started == nil:
-		mp.Fprintf(w, "analized %d boards\n", *count)
+		mp.Fprintf(w, "analized %d boards\n", count.Load())
 	case count == nil && started != nil:
 		mp.Fprintf(w, "time taken: %s\n", time.Since(*started).String())
 	}
@@ -42,10 +44,13 @@ func PrintBoard(w io.Writer, b *board.Board, count *uint64, started *time.Time)
 }
 
 var (
-	lastPrint time.Time
+	lastPrint      time.Time
+	lastPrintMutex sync.Mutex
 )
 
-func PrintBoardOnceEachInterval(interval time.Duration, w io.Writer, b *board.Board, count *uint64, started *time.Time) {
+func PrintBoardOnceEachInterval(interval time.Duration, w io.Writer, b *board.Board, count *atomic.Uint64, started *time.Time) {
+	lastPrintMutex.Lock()
+	defer lastPrintMutex.Unlock()
 	if time.Since(lastPrint) < interval {
 		return
 	}
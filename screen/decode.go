@@ -1,175 +1,338 @@
 package screen
 
 import (
-	"errors"
 	"image"
 	"image/draw"
-	"image/png"
 	"io"
-	"log"
 	"math"
 	"nonogram/bitmask"
 	"nonogram/board"
 	"nonogram/hint"
 	"os"
+	"path/filepath"
 	"slices"
 	"strconv"
 )
 
-var (
-	samples15 [10]*OneBitImage
-	samples10 [10]*OneBitImage
-	samples5  [10]*OneBitImage
+const (
+	DefaultThreshold     = .9
+	DefaultMinConfidence = .3
 
-	ErrGridSizeNotSupported = errors.New("grid size not supported")
+	maxGridSize = 30
 )
 
-func init() {
-	for c := 1; c <= 3; c++ {
-		for i := 0; i < 10; i++ {
-			filepath := "samples/" + strconv.Itoa(c*5) + "_" + strconv.Itoa(i) + ".png"
-			sample, err := loadSample(filepath)
-			if errors.Is(err, os.ErrNotExist) {
-				log.Printf("warning: sample file %q not found, skipping\n", filepath)
-				continue
-			}
-			switch c {
-			case 1:
-				samples5[i] = sample
-			case 2:
-				samples10[i] = sample
-			case 3:
-				samples15[i] = sample
-			}
-		}
+// Decoder reads puzzles from screenshots. The zero value is ready to use and
+// safe for concurrent use.
+type Decoder struct {
+	// Threshold is the brightness, from 0 to 1, above which a pixel is
+	// background. DefaultThreshold when zero.
+	Threshold float64
+	// Templates are the digit templates, DefaultTemplates when nil.
+	Templates *TemplateSet
+	// MinConfidence is the confidence, from 0 to 1, below which a clue is
+	// reported as uncertain. DefaultMinConfidence when zero.
+	MinConfidence float64
+	// Debug receives the intermediate images of every decode when not nil.
+	Debug DebugSink
+}
+
+// DebugSink receives the intermediate images of a decode, named after the
+// step that produced them, like "02-grid" or "04-vertical-cell-3-digit-0".
+type DebugSink interface {
+	Image(name string, img image.Image)
+}
+
+// DirSink writes debug images as PNG files in a directory, which must exist.
+// Errors are ignored, debugging must not break decoding.
+type DirSink string
+
+func (t DirSink) Image(name string, img image.Image) {
+	savePNG(filepath.Join(string(t), name+".png"), img)
+}
+
+func (d *Decoder) debug(name string, img image.Image) {
+	if d.Debug != nil {
+		d.Debug.Image(name, img)
 	}
 }
 
-func loadSample(filepath string) (*OneBitImage, error) {
+func DecodeFile(filepath string) (*board.Board, *hint.Hints, *Report, error) {
 	file, err := os.Open(filepath)
 	if err != nil {
-		return nil, err
+		return nil, nil, nil, err
 	}
 	defer file.Close()
-	img, err := png.Decode(file)
+	return Decode(file)
+}
+
+// Decode reads a screenshot with a Decoder using the default templates.
+func Decode(r io.Reader) (*board.Board, *hint.Hints, *Report, error) {
+	return (&Decoder{}).Decode(r)
+}
+
+// Decode reads the puzzle of a screenshot and reports the clues it is not
+// sure of. When the clues do not add up and some of them are uncertain, the
+// puzzle and the report are returned with the error, so the uncertain clues
+// can be checked.
+func (d *Decoder) Decode(r io.Reader) (*board.Board, *hint.Hints, *Report, error) {
+	templates := d.Templates
+	if templates == nil {
+		var err error
+		templates, err = DefaultTemplates()
+		if err != nil {
+			return nil, nil, nil, err
+		}
+	}
+
+	seg, err := d.segment(r)
 	if err != nil {
-		return nil, err
+		return nil, nil, nil, err
 	}
-	obi := NewOneBitImage(img.Bounds(), .9)
-	draw.Draw(obi, img.Bounds(), img, image.Pt(0, 0), draw.Src)
-	return obi, nil
-}
 
-func DecodeFile(filepath string) (*board.Board, *hint.Hints, error) {
-	file, err := os.Open(filepath)
+	samples, err := templates.Nearest(seg.size())
 	if err != nil {
-		return nil, nil, err
+		return nil, nil, nil, err
 	}
-	defer file.Close()
-	return Decode(file)
+	gap := mergeGap(samples)
+
+	minConfidence := d.MinConfidence
+	if minConfidence == 0 {
+		minConfidence = DefaultMinConfidence
+	}
+	report := &Report{}
+
+	verticalHits := [][]int{}
+	horizontalHits := [][]int{}
+	for c, digits := range seg.vertical {
+		clues := identifyNumbers(gap, samples, digits)
+		verticalHits = append(verticalHits, report.add(false, c, clues, minConfidence))
+	}
+	for c, digits := range seg.horizontal {
+		clues := identifyNumbers(gap, samples, digits)
+		horizontalHits = append(horizontalHits, report.add(true, c, clues, minConfidence))
+	}
+
+	hints := hint.New(verticalHits, horizontalHits)
+	board := readBoard(seg.grid, seg.columns, seg.rows)
+
+	if err := hints.Validate(board); err != nil {
+		if len(report.Uncertain) > 0 {
+			return board, hints, report, err
+		}
+		return nil, nil, nil, err
+	}
+
+	return board, hints, report, nil
+}
+
+// segments are the parts of a screenshot: the grid and the digit images of
+// every clue cell, in reading order.
+type segments struct {
+	grid       *OneBitImage
+	columns    int
+	rows       int
+	vertical   [][]*OneBitImage
+	horizontal [][]*OneBitImage
+}
+
+// size is the grid size digits are drawn for, as digits are drawn at the
+// scale of the cells, which is set by the longest side of the grid.
+func (t *segments) size() int {
+	return max(t.columns, t.rows)
 }
 
-func Decode(r io.Reader) (*board.Board, *hint.Hints, error) {
-	_ = os.RemoveAll("last")
-	_ = os.MkdirAll("last", 0755)
+// segment cuts a screenshot up to its digits, without reading them.
+func (d *Decoder) segment(r io.Reader) (*segments, error) {
+	threshold := d.Threshold
+	if threshold == 0 {
+		threshold = DefaultThreshold
+	}
 
 	original, _, err := image.Decode(r)
 	if err != nil {
-		return nil, nil, err
+		return nil, err
 	}
 
-	obi := NewOneBitImage(original.Bounds(), .9)
+	obi := NewOneBitImage(original.Bounds(), threshold)
 	draw.Draw(obi, obi.Bounds(), original, image.Point{original.Bounds().Min.X, original.Bounds().Min.Y}, draw.Src)
 
-	savePNG("last/00-obi.png", obi)
+	d.debug("00-obi", obi)
 
 	game := findGameArea(obi, true)
 
-	savePNG("last/01-game.png", game)
+	d.debug("01-game", game)
 
 	vertical, horizontal, grid := findGameComponents(game)
 
-	savePNG("last/02-vertical.png", vertical)
-	savePNG("last/02-horizontal.png", horizontal)
-	savePNG("last/02-grid.png", grid)
+	d.debug("02-vertical", vertical)
+	d.debug("02-horizontal", horizontal)
+	d.debug("02-grid", grid)
 
 	verticalCells := findHintCells(vertical)
 	for i, cell := range verticalCells {
-		savePNG("last/03-vertical-cell-"+strconv.Itoa(i)+".png", cell)
+		if cell != nil {
+			d.debug("03-vertical-cell-"+strconv.Itoa(i), cell)
+		}
 	}
 
 	horizontalCells := findHintCells(horizontal)
 	for i, cell := range horizontalCells {
-		savePNG("last/03-horizontal-cell-"+strconv.Itoa(i)+".png", cell)
+		if cell != nil {
+			d.debug("03-horizontal-cell-"+strconv.Itoa(i), cell)
+		}
 	}
 
-	verticalHits := [][]int{}
-	horizontalHits := [][]int{}
+	columns, rows, err := gridSize(grid)
+	if err != nil {
+		return nil, err
+	}
+	if len(verticalCells) != columns {
+		return nil, ErrClueCount{isRow: false, clues: len(verticalCells), lines: columns}
+	}
+	if len(horizontalCells) != rows {
+		return nil, ErrClueCount{isRow: true, clues: len(horizontalCells), lines: rows}
+	}
+
+	seg := &segments{grid: grid, columns: columns, rows: rows}
 	for c, cell := range verticalCells {
-		digits := split(cell, all, true)
-		for d, digit := range digits {
-			savePNG("last/04-vertical-cell-"+strconv.Itoa(c)+"-digit-"+strconv.Itoa(d)+".png", digit)
-		}
-		numbers, err := identifyNumbers(len(verticalCells), digits)
-		if err != nil {
-			return nil, nil, err
+		digits := cellDigits(cell)
+		for i, digit := range digits {
+			d.debug("04-vertical-cell-"+strconv.Itoa(c)+"-digit-"+strconv.Itoa(i), digit)
 		}
-		verticalHits = append(verticalHits, numbers)
+		seg.vertical = append(seg.vertical, digits)
 	}
 	for c, cell := range horizontalCells {
-		digits := split(cell, all, true)
-		for d, digit := range digits {
-			savePNG("last/04-horizontal-cell-"+strconv.Itoa(c)+"-digit-"+strconv.Itoa(d)+".png", digit)
-		}
-		numbers, err := identifyNumbers(len(horizontalCells), digits)
-		if err != nil {
-			return nil, nil, err
+		digits := cellDigits(cell)
+		for i, digit := range digits {
+			d.debug("04-horizontal-cell-"+strconv.Itoa(c)+"-digit-"+strconv.Itoa(i), digit)
 		}
-		horizontalHits = append(horizontalHits, numbers)
+		seg.horizontal = append(seg.horizontal, digits)
 	}
-
-	hints := hint.New(verticalHits, horizontalHits)
-	board := readBoard(grid, len(verticalHits), len(horizontalHits))
-
-	os.WriteFile("last/98-hints.txt", []byte(hints.String()), 0644)
-
-	return board, hints, nil
+	return seg, nil
 }
 
-func readBoard(obi *OneBitImage, vcount, hcount int) *board.Board {
-	b := board.New(vcount, hcount)
+func readBoard(obi *OneBitImage, columns, rows int) *board.Board {
+	b := board.New(columns, rows)
 	left, top := obi.Bounds().Min.X, obi.Bounds().Min.Y
 	width, height := obi.Bounds().Dx(), obi.Bounds().Dy()
-	svcount, shcount := vcount/5, hcount/5
-	swidth, sheight := width/svcount, height/shcount
-	subs := []*OneBitImage{}
-	for y := 0; y < shcount; y++ {
-		for x := 0; x < svcount; x++ {
-			subs = append(subs, obi.SubImage(image.Rect(left+x*swidth, top+y*sheight, left+(x+1)*swidth, top+(y+1)*sheight)).(*OneBitImage))
+	for y := 0; y < rows; y++ {
+		cellTop, cellHeight := top+y*height/rows, (y+1)*height/rows-y*height/rows
+		for x := 0; x < columns; x++ {
+			cellLeft, cellWidth := left+x*width/columns, (x+1)*width/columns-x*width/columns
+			centerX, centerY, leftX := cellLeft+cellWidth/2, cellTop+cellHeight/2, cellLeft+cellWidth/5
+			center, left := !obi.Get(centerX, centerY), !obi.Get(leftX, centerY)
+			switch {
+			case center && left:
+				b.Set(x, y, board.Filled)
+
+			case center && !left:
+				b.Set(x, y, board.Crossed)
+			}
 		}
 	}
-	for i, sub := range subs {
-		left, top := sub.Bounds().Min.X, sub.Bounds().Min.Y
-		cellWidth, cellHeight := swidth/5, sheight/5
-		for y := 0; y < 5; y++ {
-			for x := 0; x < 5; x++ {
-				centerX, centerY, leftX := left+x*cellWidth+cellWidth/2, top+y*cellHeight+cellHeight/2, left+x*cellWidth+cellWidth/5
-				center, left := !sub.Get(centerX, centerY), !sub.Get(leftX, centerY)
-				boardX, boardY := x+(i%svcount)*5, y+(i/svcount)*5
-				switch {
-				case center && left:
-					b.Set(boardX, boardY, board.Filled)
-
-				case center && !left:
-					b.Set(boardX, boardY, board.Crossed)
-				}
+	return b
+}
+
+// gridSize counts the cells of the grid. Lines are the rows and columns of
+// pixels that are almost all dark, and the cell size is the median distance
+// between them. Filled lines of cells merge grid lines, including the border,
+// so the count is the size of the grid over the cell size rather than the
+// distance between the outer lines.
+func gridSize(obi *OneBitImage) (columns, rows int, err error) {
+	columns = countCells(gridLines(obi, false), obi.Bounds().Dx())
+	rows = countCells(gridLines(obi, true), obi.Bounds().Dy())
+	if columns <= 0 || rows <= 0 || columns%5 != 0 || rows%5 != 0 || columns > maxGridSize || rows > maxGridSize {
+		return 0, 0, ErrGridSizeNotSupported{columns: columns, rows: rows}
+	}
+	return columns, rows, nil
+}
+
+// gridLines returns the center of every horizontal, or vertical, line of the
+// grid.
+func gridLines(obi *OneBitImage, horizontal bool) []float64 {
+	bounds := obi.Bounds()
+	length, across := bounds.Dx(), bounds.Dy()
+	if horizontal {
+		length, across = across, length
+	}
+	centers := []float64{}
+	start := -1
+	for i := 0; i <= length; i++ {
+		line := false
+		if i < length {
+			r := image.Rect(bounds.Min.X+i, bounds.Min.Y, bounds.Min.X+i+1, bounds.Max.Y)
+			if horizontal {
+				r = image.Rect(bounds.Min.X, bounds.Min.Y+i, bounds.Max.X, bounds.Min.Y+i+1)
 			}
+			line = count(obi, r, false)*10 >= across*9
+		}
+		switch {
+		case line && start < 0:
+			start = i
+		case !line && start >= 0:
+			centers = append(centers, float64(start+i-1)/2)
+			start = -1
 		}
 	}
-	return b
+	return centers
+}
+
+func countCells(lines []float64, length int) int {
+	if len(lines) < 2 {
+		return 0
+	}
+	gaps := make([]float64, len(lines)-1)
+	for i := 1; i < len(lines); i++ {
+		gaps[i-1] = lines[i] - lines[i-1]
+	}
+	slices.Sort(gaps)
+	cell := gaps[len(gaps)/2]
+	return int(math.Round(float64(length) / cell))
+}
+
+// mergeGap is the widest gap, in pixels, between two digits of one number:
+// half the width of the widest digit, so it follows the scale of the
+// templates.
+func mergeGap(samples [10]*OneBitImage) int {
+	widest := 0
+	for _, s := range samples {
+		widest = max(widest, s.Bounds().Dx())
+	}
+	return widest / 2
+}
+
+// identifyNumbers reads the numbers of a clue cell. Digits closer than limit
+// pixels are part of the same number.
+func identifyNumbers(limit int, samples [10]*OneBitImage, digits []*OneBitImage) []Clue {
+	digits = sortDigits(digits)
+	numbers := [][]match{}
+	for i, digit := range digits {
+		m := identifyNumber(samples, digit)
+		if i > 0 && abs(digit.Bounds().Min.X-digits[i-1].Bounds().Max.X) <= limit {
+			numbers[len(numbers)-1] = append(numbers[len(numbers)-1], m)
+			continue
+		}
+		numbers = append(numbers, []match{m})
+	}
+	clues := make([]Clue, len(numbers))
+	for i, number := range numbers {
+		clues[i] = newClue(number)
+	}
+	return clues
 }
 
-func identifyNumbers(n int, digits []*OneBitImage) ([]int, error) {
+// cellDigits cuts a clue cell into its digits, in reading order. An empty
+// cell, which findHintCells returns as nil, has none.
+func cellDigits(cell *OneBitImage) []*OneBitImage {
+	if cell == nil {
+		return nil
+	}
+	return sortDigits(split(cell, all, true))
+}
+
+// sortDigits puts the digits of a clue cell in reading order, left to right
+// and then top to bottom.
+func sortDigits(digits []*OneBitImage) []*OneBitImage {
 	slices.SortStableFunc(digits, func(a, b *OneBitImage) int {
 		amin, bmin := a.Bounds().Min, b.Bounds().Min
 		ydiff := abs(amin.Y - bmin.Y)
@@ -178,55 +341,25 @@ func identifyNumbers(n int, digits []*OneBitImage) ([]int, error) {
 		}
 		return amin.Y - bmin.Y
 	})
-	numbers := []int{}
-	for _, digit := range digits {
-		number, err := identifyNumber(n, digit)
-		if err != nil {
-			return nil, err
-		}
-		numbers = append(numbers, number)
-	}
-	var limit int
-	switch n {
-	case 15:
-		limit = 7
-	case 10:
-		limit = 8
-	default:
-		return nil, ErrGridSizeNotSupported
-	}
-	for i := 1; i < len(numbers); i++ {
-		aright := digits[i-1].Bounds().Max.X
-		bleft := digits[i].Bounds().Min.X
-		diff := abs(bleft - aright)
-		if diff <= limit {
-			numbers[i-1] = numbers[i-1]*10 + numbers[i]
-			numbers = append(numbers[:i], numbers[i+1:]...)
-		}
-	}
-	return numbers, nil
+	return digits
 }
 
-func identifyNumber(n int, obi *OneBitImage) (int, error) {
-	var samples [10]*OneBitImage
-	switch n {
-	case 5:
-		samples = samples5
-	case 10:
-		samples = samples10
-	case 15:
-		samples = samples15
-	default:
-		return -1, ErrGridSizeNotSupported
-	}
+// match is a digit read from a screenshot, with the second closest template.
+type match struct {
+	digit      int
+	runnerUp   int
+	confidence float64
+}
+
+// identifyNumber compares obi with every template. The confidence is how much
+// closer the best template is than the runner-up: 0 when they differ from obi
+// by as many pixels, 1 when the best one is a perfect match.
+func identifyNumber(samples [10]*OneBitImage, obi *OneBitImage) match {
 	left, top := obi.Bounds().Min.X, obi.Bounds().Min.Y
 	ow, oh := obi.Bounds().Dx(), obi.Bounds().Dy()
-	lowestDiff := math.MaxInt
-	lowestIndex := -1
+	lowestDiff, secondDiff := math.MaxInt, math.MaxInt
+	lowestIndex, secondIndex := -1, -1
 	for i, s := range samples {
-		if s == nil {
-			continue
-		}
 		sw, sh := s.Bounds().Dx(), s.Bounds().Dy()
 		w := max(ow, sw)
 		h := max(oh, sh)
@@ -238,12 +371,19 @@ func identifyNumber(n int, obi *OneBitImage) (int, error) {
 				}
 			}
 		}
-		if diff < lowestDiff {
-			lowestDiff = diff
-			lowestIndex = i
+		switch {
+		case diff < lowestDiff:
+			secondDiff, secondIndex = lowestDiff, lowestIndex
+			lowestDiff, lowestIndex = diff, i
+		case diff < secondDiff:
+			secondDiff, secondIndex = diff, i
 		}
 	}
-	return lowestIndex, nil
+	confidence := 0.0
+	if secondDiff > 0 {
+		confidence = 1 - float64(lowestDiff)/float64(secondDiff)
+	}
+	return match{digit: lowestIndex, runnerUp: secondIndex, confidence: confidence}
 }
 
 func findHintCells(obi *OneBitImage) []*OneBitImage {
@@ -308,6 +448,18 @@ func all(obi *OneBitImage, r image.Rectangle, value bool) bool {
 	return true
 }
 
+func count(obi *OneBitImage, r image.Rectangle, value bool) int {
+	n := 0
+	for y := r.Min.Y; y < r.Max.Y; y++ {
+		for x := r.Min.X; x < r.Max.X; x++ {
+			if obi.Get(x, y) == value {
+				n++
+			}
+		}
+	}
+	return n
+}
+
 func some(obi *OneBitImage, r image.Rectangle, value bool) bool {
 	for y := r.Min.Y; y < r.Max.Y; y++ {
 		for x := r.Min.X; x < r.Max.X; x++ {
@@ -442,12 +594,3 @@ func abs(a int) int {
 	}
 	return a
 }
-
-func savePNG(filepath string, img image.Image) error {
-	file, err := os.Create(filepath)
-	if err != nil {
-		return err
-	}
-	defer file.Close()
-	return png.Encode(file, img)
-}
@@ -0,0 +1,90 @@
+package screen
+
+import (
+	"image"
+	"nonogram/board"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// gridImage draws a grid of columns by rows cells of 10 pixels, with the
+// filled cells dark.
+func gridImage(columns, rows int, filled func(x, y int) bool) *OneBitImage {
+	width, height := columns*10+1, rows*10+1
+	obi := NewOneBitImage(image.Rect(0, 0, width, height), DefaultThreshold)
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			line := x%10 == 0 || y%10 == 0
+			if !line && !filled(x/10, y/10) {
+				obi.Pix.Set(obi.PixOffset(x, y))
+			}
+		}
+	}
+	return obi
+}
+
+func TestGridSize(t *testing.T) {
+	// The first column is filled, merging two grid lines.
+	filled := func(x, y int) bool { return x == 0 || (x == 3 && y == 7) }
+	obi := gridImage(25, 20, filled)
+	columns, rows, err := gridSize(obi)
+	require.NoError(t, err)
+	require.Equal(t, 25, columns)
+	require.Equal(t, 20, rows)
+
+	b := readBoard(obi, columns, rows)
+	require.Equal(t, board.Filled, b.Get(0, 19))
+	require.Equal(t, board.Filled, b.Get(3, 7))
+	require.Equal(t, board.Empty, b.Get(4, 7))
+
+	// Filled edge lines merge with the border of the grid.
+	for _, filled := range []func(x, y int) bool{
+		func(x, y int) bool { return x == 0 || x == 24 },
+		func(x, y int) bool { return x < 2 },
+		func(x, y int) bool { return y == 0 || y == 19 },
+	} {
+		columns, rows, err := gridSize(gridImage(25, 20, filled))
+		require.NoError(t, err)
+		require.Equal(t, 25, columns)
+		require.Equal(t, 20, rows)
+	}
+
+	_, _, err = gridSize(gridImage(12, 10, filled))
+	require.ErrorAs(t, err, &ErrGridSizeNotSupported{})
+	_, _, err = gridSize(gridImage(35, 35, filled))
+	require.ErrorAs(t, err, &ErrGridSizeNotSupported{})
+}
+
+func TestIdentifyNumbers(t *testing.T) {
+	// Template i is a bright bar i+1 pixels wide.
+	bar := func(width int) *OneBitImage {
+		obi := NewOneBitImage(image.Rect(0, 0, width, 2), DefaultThreshold)
+		obi.Negate()
+		return obi
+	}
+	samples := [10]*OneBitImage{}
+	for i := range samples {
+		samples[i] = bar(i + 1)
+	}
+	digit := func(x, width int) *OneBitImage {
+		return bar(40).SubImage(image.Rect(x, 0, x+width, 2)).(*OneBitImage)
+	}
+
+	clues := identifyNumbers(2, samples, []*OneBitImage{digit(20, 4), digit(0, 2), digit(3, 3)})
+	require.Len(t, clues, 2)
+	require.Equal(t, 12, clues[0].Value)
+	require.Equal(t, 1.0, clues[0].Confidence)
+	require.Equal(t, 3, clues[1].Value)
+
+	m := identifyNumber(samples, digit(0, 4))
+	require.Equal(t, match{digit: 3, runnerUp: 2, confidence: 1}, m)
+
+	clue := newClue([]match{{digit: 1, runnerUp: 7, confidence: .9}, {digit: 2, runnerUp: 8, confidence: .1}})
+	require.Equal(t, Clue{Value: 12, RunnerUp: 18, Confidence: .1}, clue)
+
+	report := &Report{}
+	require.Equal(t, []int{12, 3}, report.add(true, 6, []Clue{clue, {Value: 3, Confidence: 1}}, DefaultMinConfidence))
+	require.Equal(t, []Clue{{IsRow: true, Index: 6, Value: 12, RunnerUp: 18, Confidence: .1}}, report.Uncertain)
+	require.Equal(t, "row 7, number 1: 12 or 18 (10% sure)", report.Uncertain[0].String())
+}
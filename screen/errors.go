@@ -0,0 +1,44 @@
+package screen
+
+import "fmt"
+
+type ErrMissingTemplates struct {
+	size   int
+	digits []int
+}
+
+func (e ErrMissingTemplates) Error() string {
+	return fmt.Sprintf("missing digit templates %v for %dx%d grids", e.digits, e.size, e.size)
+}
+
+type ErrInvalidTemplate struct {
+	size  int
+	digit int
+}
+
+func (e ErrInvalidTemplate) Error() string {
+	return fmt.Sprintf("invalid template for digit %d of %dx%d grids", e.digit, e.size, e.size)
+}
+
+type ErrGridSizeNotSupported struct {
+	columns int
+	rows    int
+}
+
+func (e ErrGridSizeNotSupported) Error() string {
+	return fmt.Sprintf("grid size %dx%d not supported, sides must be multiples of 5 up to %d", e.columns, e.rows, maxGridSize)
+}
+
+type ErrClueCount struct {
+	isRow bool
+	clues int
+	lines int
+}
+
+func (e ErrClueCount) Error() string {
+	direction := "column"
+	if e.isRow {
+		direction = "row"
+	}
+	return fmt.Sprintf("found %d %s clues for %d %ss", e.clues, direction, e.lines, direction)
+}
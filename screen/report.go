@@ -0,0 +1,66 @@
+package screen
+
+import "fmt"
+
+// Clue is a number read from a clue cell of a screenshot. RunnerUp is the
+// number read with its least certain digit swapped for the second closest
+// template, and Confidence is the confidence of that digit.
+type Clue struct {
+	IsRow      bool
+	Index      int
+	Position   int
+	Value      int
+	RunnerUp   int
+	Confidence float64
+}
+
+func newClue(digits []match) Clue {
+	clue := Clue{Confidence: 1}
+	weakest := 0
+	for i, m := range digits {
+		clue.Value = clue.Value*10 + m.digit
+		if m.confidence < clue.Confidence {
+			clue.Confidence = m.confidence
+			weakest = i
+		}
+	}
+	for i, m := range digits {
+		digit := m.digit
+		if i == weakest {
+			digit = m.runnerUp
+		}
+		clue.RunnerUp = clue.RunnerUp*10 + digit
+	}
+	return clue
+}
+
+func (t Clue) String() string {
+	direction := "column"
+	if t.IsRow {
+		direction = "row"
+	}
+	return fmt.Sprintf("%s %d, number %d: %d or %d (%.0f%% sure)", direction, t.Index+1, t.Position+1, t.Value, t.RunnerUp, t.Confidence*100)
+}
+
+// Report lists the clues of a screenshot read with a confidence below the
+// decoder's MinConfidence, columns first.
+type Report struct {
+	Uncertain []Clue
+}
+
+// add records the uncertain clues of a line and returns its numbers. An empty
+// line is the clue 0, as Trainer.Add reads it.
+func (t *Report) add(isRow bool, index int, clues []Clue, minConfidence float64) []int {
+	if len(clues) == 0 {
+		return []int{0}
+	}
+	numbers := make([]int, len(clues))
+	for i, clue := range clues {
+		clue.IsRow, clue.Index, clue.Position = isRow, index, i
+		numbers[i] = clue.Value
+		if clue.Confidence < minConfidence {
+			t.Uncertain = append(t.Uncertain, clue)
+		}
+	}
+	return numbers
+}
@@ -0,0 +1,174 @@
+package screen
+
+import (
+	"embed"
+	"fmt"
+	"image"
+	"image/draw"
+	"image/png"
+	"io/fs"
+	"math"
+	"os"
+	"path"
+	"path/filepath"
+	"sync"
+)
+
+//go:embed samples
+var samples embed.FS
+
+// TemplateSet holds the images of the ten digits for every supported grid
+// size. Digits are drawn at a different scale for every grid size.
+type TemplateSet struct {
+	digits map[int]*[10]*OneBitImage
+}
+
+func NewTemplateSet() *TemplateSet {
+	return &TemplateSet{digits: map[int]*[10]*OneBitImage{}}
+}
+
+var defaultTemplates = sync.OnceValues(func() (*TemplateSet, error) {
+	sub, err := fs.Sub(samples, "samples")
+	if err != nil {
+		return nil, err
+	}
+	return LoadTemplates(sub)
+})
+
+// DefaultTemplates returns the templates embedded from the samples directory.
+func DefaultTemplates() (*TemplateSet, error) {
+	return defaultTemplates()
+}
+
+// LoadTemplates reads the templates named {size}_{digit}.png in the root of
+// fsys. Other files are ignored.
+func LoadTemplates(fsys fs.FS) (*TemplateSet, error) {
+	names, err := fs.Glob(fsys, "*.png")
+	if err != nil {
+		return nil, err
+	}
+	t := NewTemplateSet()
+	for _, name := range names {
+		var size, digit int
+		if _, err := fmt.Sscanf(path.Base(name), "%d_%d.png", &size, &digit); err != nil {
+			continue
+		}
+		file, err := fsys.Open(name)
+		if err != nil {
+			return nil, err
+		}
+		img, err := png.Decode(file)
+		file.Close()
+		if err != nil {
+			return nil, fmt.Errorf("template %s: %w", name, err)
+		}
+		if err := t.Add(size, digit, img); err != nil {
+			return nil, err
+		}
+	}
+	return t, nil
+}
+
+// Add sets the template of a digit for a grid size.
+func (t *TemplateSet) Add(size, digit int, img image.Image) error {
+	if digit < 0 || digit > 9 {
+		return ErrInvalidTemplate{size: size, digit: digit}
+	}
+	bounds := image.Rect(0, 0, img.Bounds().Dx(), img.Bounds().Dy())
+	obi := NewOneBitImage(bounds, .9)
+	draw.Draw(obi, bounds, img, img.Bounds().Min, draw.Src)
+	if t.digits[size] == nil {
+		t.digits[size] = &[10]*OneBitImage{}
+	}
+	t.digits[size][digit] = obi
+	return nil
+}
+
+// Digits returns the templates of a grid size, or ErrMissingTemplates when
+// any of the ten digits is missing.
+func (t *TemplateSet) Digits(size int) ([10]*OneBitImage, error) {
+	digits := t.digits[size]
+	if digits == nil {
+		return [10]*OneBitImage{}, ErrMissingTemplates{size: size, digits: []int{0, 1, 2, 3, 4, 5, 6, 7, 8, 9}}
+	}
+	missing := []int{}
+	for i, digit := range digits {
+		if digit == nil {
+			missing = append(missing, i)
+		}
+	}
+	if len(missing) > 0 {
+		return [10]*OneBitImage{}, ErrMissingTemplates{size: size, digits: missing}
+	}
+	return *digits, nil
+}
+
+// Save writes every template to dir as {size}_{digit}.png, the layout read
+// by LoadTemplates.
+func (t *TemplateSet) Save(dir string) error {
+	for size, digits := range t.digits {
+		for digit, img := range digits {
+			if img == nil {
+				continue
+			}
+			if err := savePNG(filepath.Join(dir, fmt.Sprintf("%d_%d.png", size, digit)), img); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func savePNG(name string, img image.Image) error {
+	file, err := os.Create(name)
+	if err != nil {
+		return err
+	}
+	if err := png.Encode(file, img); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// Nearest returns the templates of a grid size. When the size has no complete
+// set, the complete set of the nearest size is scaled to it, as digits shrink
+// with the cells when the grid has more of them.
+func (t *TemplateSet) Nearest(size int) ([10]*OneBitImage, error) {
+	if digits, err := t.Digits(size); err == nil {
+		return digits, nil
+	}
+	nearest := 0
+	for s := range t.digits {
+		if _, err := t.Digits(s); err != nil {
+			continue
+		}
+		if nearest == 0 || abs(s-size) < abs(nearest-size) || (abs(s-size) == abs(nearest-size) && s > nearest) {
+			nearest = s
+		}
+	}
+	if nearest == 0 {
+		return t.Digits(size)
+	}
+	digits := [10]*OneBitImage{}
+	for i, digit := range t.digits[nearest] {
+		digits[i] = scale(digit, float64(nearest)/float64(size))
+	}
+	return digits, nil
+}
+
+// scale resizes obi by factor with nearest neighbour sampling.
+func scale(obi *OneBitImage, factor float64) *OneBitImage {
+	width := max(1, int(math.Round(float64(obi.Bounds().Dx())*factor)))
+	height := max(1, int(math.Round(float64(obi.Bounds().Dy())*factor)))
+	scaled := NewOneBitImage(image.Rect(0, 0, width, height), obi.Threshold)
+	min := obi.Bounds().Min
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			if obi.Get(min.X+int(float64(x)/factor), min.Y+int(float64(y)/factor)) {
+				scaled.Pix.Set(scaled.PixOffset(x, y))
+			}
+		}
+	}
+	return scaled
+}
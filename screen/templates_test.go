@@ -0,0 +1,71 @@
+package screen
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/png"
+	"strconv"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/require"
+)
+
+func digitPNG(t *testing.T, width int) []byte {
+	img := image.NewGray(image.Rect(0, 0, width, 4))
+	for y := 0; y < 4; y++ {
+		for x := 0; x < width; x++ {
+			img.Set(x, y, color.White)
+		}
+	}
+	img.Set(0, 0, color.Black)
+	w := new(bytes.Buffer)
+	require.NoError(t, png.Encode(w, img))
+	return w.Bytes()
+}
+
+func TestLoadTemplates(t *testing.T) {
+	fsys := fstest.MapFS{"README.md": {Data: []byte("templates")}}
+	for digit := 0; digit < 10; digit++ {
+		fsys["10_"+strconv.Itoa(digit)+".png"] = &fstest.MapFile{Data: digitPNG(t, digit+1)}
+	}
+	fsys["15_3.png"] = &fstest.MapFile{Data: digitPNG(t, 2)}
+
+	templates, err := LoadTemplates(fsys)
+	require.NoError(t, err)
+	digits, err := templates.Digits(10)
+	require.NoError(t, err)
+	for i, digit := range digits {
+		require.Equal(t, i+1, digit.Bounds().Dx())
+		require.False(t, digit.Get(0, 0))
+		require.True(t, digit.Get(0, 1))
+	}
+
+	_, err = templates.Digits(15)
+	var missing ErrMissingTemplates
+	require.ErrorAs(t, err, &missing)
+	require.Equal(t, "missing digit templates [0 1 2 4 5 6 7 8 9] for 15x15 grids", missing.Error())
+
+	_, err = templates.Digits(5)
+	require.ErrorAs(t, err, &ErrMissingTemplates{})
+
+	scaled, err := templates.Nearest(20)
+	require.NoError(t, err)
+	require.Equal(t, 5, scaled[9].Bounds().Dx())
+	require.Equal(t, 2, scaled[9].Bounds().Dy())
+	_, err = NewTemplateSet().Nearest(20)
+	require.ErrorAs(t, err, &ErrMissingTemplates{})
+
+	require.ErrorAs(t, templates.Add(10, 10, image.NewGray(image.Rect(0, 0, 1, 1))), &ErrInvalidTemplate{})
+
+}
+
+func TestDefaultTemplates(t *testing.T) {
+	templates, err := DefaultTemplates()
+	require.NoError(t, err)
+	for _, size := range []int{10, 15} {
+		_, err := templates.Digits(size)
+		require.NoError(t, err)
+	}
+}
@@ -0,0 +1,128 @@
+package screen
+
+import (
+	"image"
+	"io"
+	"nonogram/hint"
+	"slices"
+	"strconv"
+)
+
+// Trainer makes digit templates out of screenshots of puzzles with known
+// clues, for devices and themes the embedded templates were not cut from.
+type Trainer struct {
+	// Decoder cuts the screenshots up, the zero Decoder when nil.
+	Decoder  *Decoder
+	examples map[int]*[10][]*OneBitImage
+}
+
+// Add collects the digits of a screenshot of the puzzle with hints h. Clue
+// cells with a different number of digits than their clue, usually because
+// two digits touch, are left out and counted in skipped.
+func (t *Trainer) Add(r io.Reader, h *hint.Hints) (skipped int, err error) {
+	d := t.Decoder
+	if d == nil {
+		d = &Decoder{}
+	}
+	seg, err := d.segment(r)
+	if err != nil {
+		return 0, err
+	}
+	if len(h.Vertical) != seg.columns {
+		return 0, ErrClueCount{isRow: false, clues: len(h.Vertical), lines: seg.columns}
+	}
+	if len(h.Horizontal) != seg.rows {
+		return 0, ErrClueCount{isRow: true, clues: len(h.Horizontal), lines: seg.rows}
+	}
+	if t.examples == nil {
+		t.examples = map[int]*[10][]*OneBitImage{}
+	}
+	examples := t.examples[seg.size()]
+	if examples == nil {
+		examples = &[10][]*OneBitImage{}
+		t.examples[seg.size()] = examples
+	}
+	cells := append(slices.Clone(seg.vertical), seg.horizontal...)
+	clues := append(slices.Clone(h.Vertical), h.Horizontal...)
+	for i, digits := range cells {
+		expected := clueDigits(clues[i])
+		if len(digits) == 0 && slices.Equal(expected, []int{0}) {
+			continue
+		}
+		if len(digits) != len(expected) {
+			skipped++
+			continue
+		}
+		for j, digit := range digits {
+			examples[expected[j]] = append(examples[expected[j]], digit)
+		}
+	}
+	return skipped, nil
+}
+
+// clueDigits returns the digits of a clue as they are drawn.
+func clueDigits(clue []int) []int {
+	digits := []int{}
+	for _, n := range clue {
+		for _, c := range strconv.Itoa(n) {
+			digits = append(digits, int(c-'0'))
+		}
+	}
+	return digits
+}
+
+// Counts returns the number of examples of every digit for every grid size.
+func (t *Trainer) Counts() map[int][10]int {
+	counts := map[int][10]int{}
+	for size, examples := range t.examples {
+		c := [10]int{}
+		for digit, images := range examples {
+			c[digit] = len(images)
+		}
+		counts[size] = c
+	}
+	return counts
+}
+
+// Templates merges the examples of every digit into one template. The
+// examples are aligned on their top left corner, like digits are compared in
+// decoding, the template has their median size and a pixel is bright when it
+// is bright in most examples.
+func (t *Trainer) Templates() *TemplateSet {
+	set := NewTemplateSet()
+	for size, examples := range t.examples {
+		for digit, images := range examples {
+			if len(images) == 0 {
+				continue
+			}
+			set.Add(size, digit, merge(images))
+		}
+	}
+	return set
+}
+
+func merge(images []*OneBitImage) *OneBitImage {
+	widths := make([]int, len(images))
+	heights := make([]int, len(images))
+	for i, img := range images {
+		widths[i], heights[i] = img.Bounds().Dx(), img.Bounds().Dy()
+	}
+	slices.Sort(widths)
+	slices.Sort(heights)
+	width, height := widths[len(widths)/2], heights[len(heights)/2]
+	merged := NewOneBitImage(image.Rect(0, 0, width, height), DefaultThreshold)
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			bright := 0
+			for _, img := range images {
+				if img.Get(img.Bounds().Min.X+x, img.Bounds().Min.Y+y) {
+					bright++
+				}
+			}
+			if bright*2 > len(images) {
+				merged.Pix.Set(merged.PixOffset(x, y))
+			}
+		}
+	}
+	return merged
+}
@@ -0,0 +1,143 @@
+package screen
+
+import (
+	"bytes"
+	"image"
+	"image/draw"
+	"image/png"
+	"nonogram/hint"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestTrainer(t *testing.T) {
+	require.Equal(t, []int{1, 2, 0, 3}, clueDigits([]int{12, 0, 3}))
+
+	// Three examples of a digit, one a pixel wider and one with a stray dark
+	// pixel, merge into the common shape.
+	example := func(width int, dark ...image.Point) *OneBitImage {
+		obi := NewOneBitImage(image.Rect(0, 0, 20, 20), DefaultThreshold)
+		obi.Negate()
+		for _, p := range dark {
+			obi.Pix.Clear(obi.PixOffset(10+p.X, 10+p.Y))
+		}
+		return obi.SubImage(image.Rect(10, 10, 10+width, 13)).(*OneBitImage)
+	}
+	trainer := &Trainer{examples: map[int]*[10][]*OneBitImage{20: {7: {
+		example(2, image.Point{0, 0}),
+		example(3, image.Point{0, 0}, image.Point{2, 2}),
+		example(2, image.Point{1, 1}),
+	}}}}
+	require.Equal(t, 3, trainer.Counts()[20][7])
+
+	dir := t.TempDir()
+	require.NoError(t, trainer.Templates().Save(dir))
+	templates, err := LoadTemplates(os.DirFS(dir))
+	require.NoError(t, err)
+	require.Equal(t, image.Rect(0, 0, 2, 3), templates.digits[20][7].Bounds())
+	require.False(t, templates.digits[20][7].Get(0, 0))
+	require.True(t, templates.digits[20][7].Get(1, 1))
+}
+
+// screenshot draws a puzzle screen with the clues of a 5x5 grid of 20 pixel
+// cells. Digit d is a dark bar 4 pixels wide and 5+d high, and the digits of
+// row touching are drawn without a gap between them.
+func screenshot(t *testing.T, columns, rows [][]int, touching int) []byte {
+	img := image.NewGray(image.Rect(0, 0, 200, 240))
+	draw.Draw(img, img.Bounds(), image.White, image.Point{}, draw.Src)
+	dark := func(r image.Rectangle) {
+		draw.Draw(img, r, image.Black, image.Point{}, draw.Src)
+	}
+	box := func(r image.Rectangle) {
+		dark(r)
+		draw.Draw(img, r.Inset(1), image.White, image.Point{}, draw.Src)
+	}
+
+	// Three bars above the puzzle, like the title and the buttons of the app.
+	for y := 5; y < 30; y += 10 {
+		dark(image.Rect(0, y, 200, y+5))
+	}
+
+	gx, gy := 80, 120
+	for i := 0; i <= 5; i++ {
+		dark(image.Rect(gx+i*20, gy, gx+i*20+1, gy+101))
+		dark(image.Rect(gx, gy+i*20, gx+101, gy+i*20+1))
+	}
+	for i, clue := range columns {
+		left := gx + i*20 + 2
+		box(image.Rect(left, 70, left+16, 110))
+		y := 73
+		for _, n := range clue {
+			dark(image.Rect(left+3, y, left+7, y+5+n))
+			y += 5 + n + 3
+		}
+	}
+	for i, clue := range rows {
+		top := gy + i*20 + 2
+		box(image.Rect(10, top, 70, top+16))
+		gap := 3
+		if i == touching {
+			gap = 0
+		}
+		x := 13
+		for _, n := range clue {
+			if n == 0 {
+				continue
+			}
+			dark(image.Rect(x, top+3, x+4, top+3+5+n))
+			x += 4 + gap
+		}
+	}
+
+	w := new(bytes.Buffer)
+	require.NoError(t, png.Encode(w, img))
+	return w.Bytes()
+}
+
+func TestTrainerAdd(t *testing.T) {
+	columns := [][]int{{2, 2}, {1, 2}, {2, 1}, {2}, {2}}
+	rows := [][]int{{1, 1}, {3}, {0}, {2, 2}, {5}}
+	shot := screenshot(t, columns, rows, 0)
+
+	trainer := &Trainer{}
+	skipped, err := trainer.Add(bytes.NewReader(shot), hint.New(columns, rows))
+	require.NoError(t, err)
+	require.Equal(t, 1, skipped)
+	require.Equal(t, [10]int{1: 2, 2: 8, 3: 1, 5: 1}, trainer.Counts()[5])
+
+	templates := trainer.Templates()
+	two := templates.digits[5][2]
+	require.Equal(t, image.Rect(0, 0, 4, 7), two.Bounds())
+	require.False(t, two.Get(3, 6))
+
+	_, err = trainer.Add(bytes.NewReader(shot), hint.New(columns[:4], rows))
+	require.ErrorAs(t, err, &ErrClueCount{})
+	_, err = trainer.Add(bytes.NewReader(shot), hint.New(columns, rows[:4]))
+	require.ErrorAs(t, err, &ErrClueCount{})
+}
+
+func TestDecodeEmptyClue(t *testing.T) {
+	// The clues are all 1s, as the bars screenshot draws for other digits
+	// only differ in height, and the third row is empty.
+	columns := [][]int{{1}, {1, 1}, {1}, {1}, {1}}
+	rows := [][]int{{1}, {1}, {0}, {1, 1}, {1, 1}}
+	shot := screenshot(t, columns, rows, -1)
+
+	trainer := &Trainer{}
+	_, err := trainer.Add(bytes.NewReader(shot), hint.New(columns, rows))
+	require.NoError(t, err)
+	templates := trainer.Templates()
+	for d := 0; d < 10; d++ {
+		if d != 1 {
+			blank := NewOneBitImage(image.Rect(0, 0, 4, 6), DefaultThreshold)
+			blank.Negate()
+			templates.Add(5, d, blank)
+		}
+	}
+
+	_, h, _, err := (&Decoder{Templates: templates}).Decode(bytes.NewReader(shot))
+	require.NoError(t, err)
+	require.Equal(t, hint.New(columns, rows), h)
+}
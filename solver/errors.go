@@ -0,0 +1,23 @@
+package solver
+
+type ErrNoDeduction struct {
+}
+
+func (e ErrNoDeduction) Error() string {
+	return "no cell can be deduced from a single line"
+}
+
+type ErrUnknownSolver struct {
+	name string
+}
+
+func (e ErrUnknownSolver) Error() string {
+	return "unknown solver " + e.name
+}
+
+type ErrNoSolution struct {
+}
+
+func (e ErrNoSolution) Error() string {
+	return "the puzzle has no solution"
+}
@@ -0,0 +1,97 @@
+package solver
+
+import (
+	"context"
+	"nonogram/board"
+	"nonogram/hint"
+	"time"
+)
+
+type StepKind string
+
+const (
+	// StepDeduce fixes cells that follow from the hints of a single line.
+	StepDeduce StepKind = "deduce"
+	// StepGuess sets a cell without proof to explore one branch.
+	StepGuess StepKind = "guess"
+	// StepBacktrack undoes a guess, and every step after it, because the
+	// branch has no solution.
+	StepBacktrack StepKind = "backtrack"
+)
+
+type Rule string
+
+const (
+	// RuleOverlap fixes the cells covered by a block in both its left-most
+	// and right-most placement, and crosses the cells no block can reach.
+	RuleOverlap Rule = "overlap"
+	// RuleLine fixes the cells that have the same state in every placement
+	// of the line's blocks.
+	RuleLine Rule = "line"
+	// RuleProbe fixes the cells that follow from both states of the probed
+	// cell, or from the only state that does not lead to a contradiction.
+	RuleProbe Rule = "probe"
+)
+
+type Direction string
+
+const (
+	Row    Direction = "row"
+	Column Direction = "column"
+)
+
+type Cell struct {
+	X      int  `json:"x"`
+	Y      int  `json:"y"`
+	Filled bool `json:"filled"`
+}
+
+// Step is one entry of an explanation. Rule is only set for deduce steps,
+// Direction and Index for line deductions and Probe for probe deductions.
+type Step struct {
+	Kind      StepKind  `json:"kind"`
+	Depth     int       `json:"depth"`
+	Rule      Rule      `json:"rule,omitempty"`
+	Direction Direction `json:"direction,omitempty"`
+	Index     int       `json:"index"`
+	Probe     *Cell     `json:"probe,omitempty"`
+	Cells     []Cell    `json:"cells"`
+}
+
+// Apply sets the cells of the step on b.
+func (t Step) Apply(b *board.Board) {
+	for _, cell := range t.Cells {
+		if cell.Filled {
+			b.Set(cell.X, cell.Y, board.Filled)
+		} else {
+			b.Set(cell.X, cell.Y, board.Crossed)
+		}
+	}
+}
+
+// Explain solves the puzzle like Solve and also returns every step taken to
+// reach the solution, in order. Depth is the number of guesses the step
+// depends on.
+func Explain(ctx context.Context, b *board.Board, h *hint.Hints) (*board.Board, []Step, Stats, error) {
+	stats := Stats{Start: time.Now()}
+
+	steps := []Step{}
+	depth := 0
+	s := newSearch(h, &stats, func(*board.Board) bool { return true })
+	s.record = func(step Step) {
+		if step.Kind == StepGuess {
+			depth++
+		}
+		step.Depth = depth
+		if step.Kind == StepBacktrack {
+			depth--
+		}
+		steps = append(steps, step)
+	}
+	solved, err := s.check(ctx, b.Clone(), true)
+	s.collect()
+	if err != nil {
+		return nil, steps, stats, err
+	}
+	return solved, steps, stats, nil
+}
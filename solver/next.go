@@ -0,0 +1,35 @@
+package solver
+
+import (
+	"nonogram/board"
+	"nonogram/hint"
+)
+
+// Next returns the deduce step that fixes the fewest new cells using the
+// hints of a single line, without changing b. It returns ErrNoDeduction when
+// no line forces any cell, and the hint errors when b contradicts h.
+func Next(b *board.Board, h *hint.Hints) (Step, error) {
+	_, err := h.Check(b)
+	if err != nil {
+		return Step{}, err
+	}
+
+	width, height := b.Size()
+	best := Step{}
+	for _, direction := range []Direction{Row, Column} {
+		count := height
+		if direction == Column {
+			count = width
+		}
+		for index := 0; index < count; index++ {
+			step, _ := solveLine(b, h, RuleLine, direction, index)
+			if len(step.Cells) > 0 && (len(best.Cells) == 0 || len(step.Cells) < len(best.Cells)) {
+				best = step
+			}
+		}
+	}
+	if len(best.Cells) == 0 {
+		return Step{}, ErrNoDeduction{}
+	}
+	return best, nil
+}
@@ -0,0 +1,190 @@
+package solver
+
+import (
+	"context"
+	"nonogram/board"
+	"nonogram/hint"
+	"runtime"
+	"sync"
+	"time"
+)
+
+// SolveParallel behaves like SolveAll but splits the search tree on its first
+// guesses and explores the branches on GOMAXPROCS workers. Once limit
+// solutions are found the remaining workers are cancelled.
+func SolveParallel(ctx context.Context, b *board.Board, h *hint.Hints, limit int) ([]*board.Board, Stats, error) {
+	stats := Stats{Start: time.Now()}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var mutex sync.Mutex
+	solutions := []*board.Board{}
+	enough := false
+	found := func(solved *board.Board) bool {
+		mutex.Lock()
+		defer mutex.Unlock()
+		if enough {
+			return true
+		}
+		solutions = append(solutions, solved)
+		if limit > 0 && len(solutions) >= limit {
+			enough = true
+			cancel()
+		}
+		return enough
+	}
+
+	workers := runtime.GOMAXPROCS(0)
+	s := newSearch(h, &stats, found)
+	s.proven = b.Clone()
+	branches, leaves, err := s.split(ctx, s.proven, workers*4)
+	stats.Proven = s.proven
+	if err != nil {
+		s.collect()
+		return solutions, stats, err
+	}
+	err = s.explore(ctx, branches, leaves, workers)
+	s.collect()
+
+	mutex.Lock()
+	defer mutex.Unlock()
+	if enough {
+		return solutions, stats, nil
+	}
+	if err != nil {
+		return solutions, stats, err
+	}
+	return solutions, stats, context.Cause(ctx)
+}
+
+// explore searches the branches on workers goroutines and returns the first
+// error of a worker. It sets the proven board to the cells that the solved
+// leaves of the split and every branch still able to hold a solution agree
+// on: the proven board of each subtree moves forward on its own, and branches
+// found to be dead ends drop out.
+func (s *search) explore(ctx context.Context, branches, leaves []*board.Board, workers int) error {
+	if len(branches) == 0 {
+		return nil
+	}
+	ctx, cancel := context.WithCancelCause(ctx)
+	defer cancel(nil)
+
+	subs := make([]*search, len(branches))
+	for i, branch := range branches {
+		subs[i] = s.branch(branch)
+	}
+	dead := make([]bool, len(branches))
+
+	var mutex sync.Mutex
+	var firstErr error
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				_, err := subs[i].solve(ctx, branches[i])
+				if err == nil {
+					dead[i] = !subs[i].solved
+					continue
+				}
+				mutex.Lock()
+				if firstErr == nil {
+					firstErr = err
+				}
+				mutex.Unlock()
+				cancel(err)
+			}
+		}()
+	}
+
+feed:
+	for i := range branches {
+		select {
+		case jobs <- i:
+		case <-ctx.Done():
+			break feed
+		}
+	}
+	close(jobs)
+	wg.Wait()
+
+	proven := leaves
+	for i, sub := range subs {
+		if !dead[i] {
+			proven = append(proven, sub.proven)
+		}
+	}
+	if len(proven) > 0 {
+		s.stats.Proven = intersect(proven)
+	}
+	return firstErr
+}
+
+// split expands the search tree breadth first until there are at least n open
+// branches. Branches that are solved while splitting are passed to found and
+// returned as leaves, and no branches are returned once found asks to stop.
+func (s *search) split(ctx context.Context, b *board.Board, n int) (branches, leaves []*board.Board, err error) {
+	s.counters.count.Add(1)
+
+	valid, done, err := s.settle(ctx, b, true)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !valid {
+		return nil, nil, nil
+	}
+	if done {
+		s.accept(b)
+		return nil, nil, nil
+	}
+
+	branches = []*board.Board{b}
+	for len(branches) < n {
+		select {
+		case <-ctx.Done():
+			return nil, nil, context.Cause(ctx)
+		default:
+		}
+
+		next := []*board.Board{}
+		for _, branch := range branches {
+			open := []*board.Board{}
+			x, y := nextEmpty(branch, s.vOrder, s.hOrder)
+			for _, state := range []board.CellState{board.Filled, board.Crossed} {
+				c := branch.Clone()
+				err := c.Set(x, y, state)
+				if err != nil {
+					return nil, nil, err
+				}
+				s.counters.count.Add(1)
+				valid, done, err := s.settle(ctx, c, false)
+				if err != nil {
+					return nil, nil, err
+				}
+				if !valid {
+					continue
+				}
+				if !done {
+					open = append(open, c)
+					continue
+				}
+				leaves = append(leaves, c)
+				if s.accept(c) {
+					return nil, nil, nil
+				}
+			}
+			if len(open) == 1 {
+				s.prove(branch, open[0])
+			}
+			next = append(next, open...)
+		}
+		if len(next) == 0 {
+			return nil, leaves, nil
+		}
+		branches = next
+	}
+	return branches, leaves, nil
+}
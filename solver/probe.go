@@ -0,0 +1,79 @@
+package solver
+
+import (
+	"context"
+	"nonogram/board"
+	"nonogram/hint"
+)
+
+// probe tries every empty cell of b as filled and as crossed and propagates
+// both boards. When one of them contradicts the hints the other one is kept,
+// and when both are consistent the cells they agree on are kept. It repeats
+// until no cell changes and returns the number of cells it fixed, or false
+// when a cell can be neither filled nor crossed. It stops with the cause when
+// ctx is cancelled.
+func probe(ctx context.Context, b *board.Board, h *hint.Hints, record func(Step)) (bool, int, error) {
+	width, height := b.Size()
+	fixed := 0
+	for progress := true; progress; {
+		progress = false
+		for y := 0; y < height; y++ {
+			for x := 0; x < width; x++ {
+				if err := context.Cause(ctx); err != nil {
+					return true, fixed, err
+				}
+				if b.Get(x, y) != board.Empty {
+					continue
+				}
+				filled := b.Clone()
+				filled.Set(x, y, board.Filled)
+				filledValid := propagate(filled, h, nil)
+				crossed := b.Clone()
+				crossed.Set(x, y, board.Crossed)
+				crossedValid := propagate(crossed, h, nil)
+
+				step := Step{Kind: StepDeduce, Rule: RuleProbe, Probe: &Cell{X: x, Y: y}, Cells: []Cell{}}
+				switch {
+				case !filledValid && !crossedValid:
+					return false, fixed, nil
+				case !filledValid:
+					step.Cells = known(b, crossed, crossed)
+				case !crossedValid:
+					step.Cells = known(b, filled, filled)
+				default:
+					step.Cells = known(b, filled, crossed)
+				}
+				if len(step.Cells) == 0 {
+					continue
+				}
+				step.Apply(b)
+				if record != nil {
+					record(step)
+				}
+				fixed += len(step.Cells)
+				progress = true
+				if !propagate(b, h, record) {
+					return false, fixed, nil
+				}
+			}
+		}
+	}
+	return true, fixed, nil
+}
+
+// known returns the cells that are empty in b and have the same known state
+// in both a and c.
+func known(b, a, c *board.Board) []Cell {
+	width, height := b.Size()
+	cells := []Cell{}
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			state := a.Get(x, y)
+			if b.Get(x, y) != board.Empty || state == board.Empty || state != c.Get(x, y) {
+				continue
+			}
+			cells = append(cells, Cell{X: x, Y: y, Filled: state == board.Filled})
+		}
+	}
+	return cells
+}
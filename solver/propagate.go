@@ -0,0 +1,112 @@
+package solver
+
+import (
+	"nonogram/board"
+	"nonogram/hint"
+	"nonogram/line"
+)
+
+// propagate solves every row and column of b with line logic, revisiting the
+// lines crossed by newly fixed cells until nothing changes. It returns false
+// when a line has no placement consistent with the board. Every line that
+// fixes new cells is passed to record, when it is not nil.
+func propagate(b *board.Board, h *hint.Hints, record func(Step)) bool {
+	return propagateRule(b, h, RuleLine, record)
+}
+
+// propagateRule is propagate with the line rule to apply, RuleLine or the
+// weaker RuleOverlap.
+func propagateRule(b *board.Board, h *hint.Hints, rule Rule, record func(Step)) bool {
+	width, height := b.Size()
+	if len(h.Vertical) != width || len(h.Horizontal) != height {
+		return true
+	}
+
+	rows := make([]bool, height)
+	columns := make([]bool, width)
+	for y := range rows {
+		rows[y] = true
+	}
+	for x := range columns {
+		columns[x] = true
+	}
+
+	for dirty := true; dirty; {
+		dirty = false
+		for y := range rows {
+			if !rows[y] {
+				continue
+			}
+			rows[y] = false
+			step, ok := solveLine(b, h, rule, Row, y)
+			if !ok {
+				return false
+			}
+			if len(step.Cells) == 0 {
+				continue
+			}
+			for _, cell := range step.Cells {
+				columns[cell.X] = true
+			}
+			dirty = true
+			step.Apply(b)
+			if record != nil {
+				record(step)
+			}
+		}
+		for x := range columns {
+			if !columns[x] {
+				continue
+			}
+			columns[x] = false
+			step, ok := solveLine(b, h, rule, Column, x)
+			if !ok {
+				return false
+			}
+			if len(step.Cells) == 0 {
+				continue
+			}
+			for _, cell := range step.Cells {
+				rows[cell.Y] = true
+			}
+			dirty = true
+			step.Apply(b)
+			if record != nil {
+				record(step)
+			}
+		}
+	}
+	return true
+}
+
+// solveLine returns the deduce step with the cells of one line that line
+// logic fixes with rule, or false when the line has no consistent placement.
+func solveLine(b *board.Board, h *hint.Hints, rule Rule, direction Direction, index int) (Step, bool) {
+	var cells []board.CellState
+	var clue []int
+	if direction == Row {
+		cells, clue = b.Row(index), h.Horizontal[index]
+	} else {
+		cells, clue = b.Column(index), h.Vertical[index]
+	}
+	solve := line.Solve
+	if rule == RuleOverlap {
+		solve = line.Overlap
+	}
+	solved, ok := solve(cells, clue)
+	if !ok {
+		return Step{}, false
+	}
+	step := Step{Kind: StepDeduce, Rule: rule, Direction: direction, Index: index, Cells: []Cell{}}
+	for i := range solved {
+		if solved[i] == cells[i] {
+			continue
+		}
+		cell := Cell{X: i, Y: index, Filled: solved[i] == board.Filled}
+		if direction == Column {
+			cell.X, cell.Y = index, i
+		}
+		step.Cells = append(step.Cells, cell)
+	}
+	return step, true
+}
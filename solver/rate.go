@@ -0,0 +1,101 @@
+package solver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"nonogram/board"
+	"nonogram/hint"
+	"strings"
+)
+
+// Difficulty rates a puzzle by the weakest techniques that solve it. Score
+// grows with the strongest technique needed: 100 for overlap alone, 200 for
+// full line logic, 300 plus the number of probed cells for probing and 400
+// plus the number of guesses for guessing, each capped at 99 so puzzles of a
+// weaker level always sort first.
+type Difficulty struct {
+	Score int `json:"score"`
+	// Techniques are the techniques that fixed at least one cell, from the
+	// weakest to the strongest. Guessing is listed as "guess".
+	Techniques []Rule `json:"techniques"`
+	Probed     int    `json:"probed"`
+	Guesses    int    `json:"guesses"`
+	// Depth is the largest number of guesses in effect at the same time.
+	Depth int `json:"depth"`
+}
+
+// RuleGuess only names guessing in Difficulty.Techniques, guesses are not
+// deductions.
+const RuleGuess Rule = "guess"
+
+func (t Difficulty) String() string {
+	techniques := make([]string, len(t.Techniques))
+	for i, technique := range t.Techniques {
+		techniques[i] = string(technique)
+	}
+	return fmt.Sprintf("%d (%s)", t.Score, strings.Join(techniques, ", "))
+}
+
+// Rate solves the puzzle with increasingly strong techniques and returns the
+// difficulty of the first level that solves it. Guessing only looks for the
+// first solution, so Rate does not tell whether the solution is unique.
+func Rate(ctx context.Context, b *board.Board, h *hint.Hints) (Difficulty, error) {
+	_, err := h.Check(b)
+	if errors.As(err, &hint.ErrInvalid{}) {
+		return Difficulty{}, ErrNoSolution{}
+	}
+	if err != nil {
+		return Difficulty{}, err
+	}
+
+	d := Difficulty{Techniques: []Rule{}}
+	c := b.Clone()
+	empty := c.Count(board.Empty)
+	for i, rule := range []Rule{RuleOverlap, RuleLine} {
+		if !propagateRule(c, h, rule, nil) {
+			return Difficulty{}, ErrNoSolution{}
+		}
+		if c.Count(board.Empty) < empty {
+			d.Techniques = append(d.Techniques, rule)
+			d.Score = 100 * (i + 1)
+			empty = c.Count(board.Empty)
+		}
+		if empty == 0 {
+			return d, nil
+		}
+	}
+
+	valid, probed, err := probe(ctx, c, h, nil)
+	if err != nil {
+		return Difficulty{}, err
+	}
+	if !valid {
+		return Difficulty{}, ErrNoSolution{}
+	}
+	if probed > 0 {
+		d.Techniques = append(d.Techniques, RuleProbe)
+		d.Probed = probed
+		d.Score = 300 + min(probed, 99)
+	}
+	if c.Count(board.Empty) == 0 {
+		return d, nil
+	}
+
+	solved, steps, _, err := Explain(ctx, c, h)
+	if err != nil {
+		return Difficulty{}, err
+	}
+	if solved == nil {
+		return Difficulty{}, ErrNoSolution{}
+	}
+	for _, step := range steps {
+		if step.Kind == StepGuess {
+			d.Guesses++
+			d.Depth = max(d.Depth, step.Depth)
+		}
+	}
+	d.Techniques = append(d.Techniques, RuleGuess)
+	d.Score = 400 + min(d.Guesses, 99)
+	return d, nil
+}
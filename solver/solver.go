@@ -9,6 +9,8 @@ import (
 	"os"
 	"runtime"
 	"slices"
+	"sync"
+	"sync/atomic"
 	"time"
 )
 
@@ -18,57 +20,157 @@ type score struct {
 	filledCellCount int
 }
 
-type stats struct {
-	Start time.Time
-	Count uint64
+// Stats describes the work a solver did on a puzzle.
+type Stats struct {
+	Start time.Time `json:"start"`
+	// Count is the number of boards checked.
+	Count uint64 `json:"count"`
+	// Probed is the number of cells fixed by probing rather than by the
+	// hints of a single line.
+	Probed uint64 `json:"probed"`
+	// Proven holds every cell that is known regardless of the branch that
+	// leads to a solution. It is the best partial answer when the search is
+	// cancelled before finding a solution.
+	Proven *board.Board `json:"-"`
 }
 
-func Solve(ctx context.Context, b *board.Board, h *hint.Hints) (*board.Board, stats, error) {
-	stats := stats{Start: time.Now()}
+// counters are the stats that workers update concurrently. They are kept out
+// of stats, which is copied around, and loaded into it when the search ends.
+type counters struct {
+	count  atomic.Uint64
+	probed atomic.Uint64
+}
+
+// search holds what stays the same while exploring the branches of one
+// puzzle. It is shared between the workers of SolveParallel.
+type search struct {
+	h        *hint.Hints
+	vOrder   []score
+	hOrder   []score
+	stats    *Stats
+	counters *counters
+	found    func(*board.Board) bool
+	record   func(Step)
+
+	mutex  sync.Mutex
+	solved bool
+	// proven is the board of the only branch left to explore, see
+	// stats.Proven.
+	proven *board.Board
+}
 
+func newSearch(h *hint.Hints, stats *Stats, found func(*board.Board) bool) *search {
 	vOrder, hOrder := solveOrder(h)
-	solved, err := solve(ctx, b, h, vOrder, hOrder, &stats)
+	return &search{
+		h:        h,
+		vOrder:   vOrder,
+		hOrder:   hOrder,
+		stats:    stats,
+		counters: &counters{},
+		found:    found,
+	}
+}
+
+// collect loads the counters into the stats once the search is over.
+func (s *search) collect() {
+	s.stats.Count = s.counters.count.Load()
+	s.stats.Probed = s.counters.probed.Load()
+}
+
+func Solve(ctx context.Context, b *board.Board, h *hint.Hints) (*board.Board, Stats, error) {
+	stats := Stats{Start: time.Now()}
+
+	s := newSearch(h, &stats, func(*board.Board) bool { return true })
+	s.proven = b.Clone()
+	solved, err := s.check(ctx, s.proven, true)
+	stats.Proven = s.proven
+	s.collect()
 	if err != nil {
-		return nil, stats, err
+		return stats.Proven, stats, err
 	}
 	return solved, stats, nil
 }
 
-func solve(ctx context.Context, b *board.Board, h *hint.Hints, vOrder, hOrder []score, stats *stats) (*board.Board, error) {
+// SolveAll keeps searching after the first solution and returns up to limit
+// distinct solutions, or all of them when limit is not positive. A puzzle
+// with a unique solution returns exactly one board.
+func SolveAll(ctx context.Context, b *board.Board, h *hint.Hints, limit int) ([]*board.Board, Stats, error) {
+	stats := Stats{Start: time.Now()}
+
+	solutions := []*board.Board{}
+	s := newSearch(h, &stats, func(solved *board.Board) bool {
+		solutions = append(solutions, solved)
+		return limit > 0 && len(solutions) >= limit
+	})
+	s.proven = b.Clone()
+	_, err := s.check(ctx, s.proven, true)
+	stats.Proven = s.proven
+	s.collect()
+	if err != nil {
+		return solutions, stats, err
+	}
+	return solutions, stats, nil
+}
+
+func (s *search) solve(ctx context.Context, b *board.Board) (*board.Board, error) {
 	runtime.Gosched()
 
 	select {
 	case <-ctx.Done():
-		return nil, ctx.Err()
+		return nil, context.Cause(ctx)
 	default:
 	}
 
-	printer.PrintBoardOnceEachInterval(3*time.Second, os.Stderr, b, &stats.Count, &stats.Start)
+	printer.PrintBoardOnceEachInterval(3*time.Second, os.Stderr, b, &s.counters.count, &s.stats.Start)
 
-	x, y := nextEmpty(b, vOrder, hOrder)
+	x, y := nextEmpty(b, s.vOrder, s.hOrder)
 	if x < 0 || y < 0 {
 		return nil, nil
 	}
 	if b.Get(x, y) != board.Empty {
 		return nil, nil
 	}
-	c := b.Clone()
-	err := c.Set(x, y, board.Filled)
-	if err != nil {
-		return nil, err
+	for i, state := range []board.CellState{board.Filled, board.Crossed} {
+		c := b.Clone()
+		err := c.Set(x, y, state)
+		if err != nil {
+			return nil, err
+		}
+		if i > 0 {
+			s.prove(b, c)
+		}
+		s.step(Step{Kind: StepGuess, Cells: []Cell{{X: x, Y: y, Filled: state == board.Filled}}})
+		solved, err := s.check(ctx, c, false)
+		if err != nil {
+			return nil, err
+		}
+		if solved != nil {
+			return solved, nil
+		}
+		s.step(Step{Kind: StepBacktrack, Cells: []Cell{{X: x, Y: y, Filled: state == board.Filled}}})
 	}
-	solved, err := check(ctx, c, h, vOrder, hOrder, stats)
+	return nil, nil
+}
+
+// check settles b and searches it. Only the root of the search is probed,
+// probing every node costs more than the guesses it saves.
+func (s *search) check(ctx context.Context, b *board.Board, probing bool) (*board.Board, error) {
+	s.counters.count.Add(1)
+
+	valid, done, err := s.settle(ctx, b, probing)
 	if err != nil {
 		return nil, err
 	}
-	if solved != nil {
-		return solved, nil
+	if !valid {
+		return nil, nil
 	}
-	err = c.Set(x, y, board.Crossed)
-	if err != nil {
-		return nil, err
+	if done {
+		if s.accept(b) {
+			return b, nil
+		}
+		return nil, nil
 	}
-	solved, err = check(ctx, c, h, vOrder, hOrder, stats)
+	solved, err := s.solve(ctx, b)
 	if err != nil {
 		return nil, err
 	}
@@ -78,27 +180,101 @@ func solve(ctx context.Context, b *board.Board, h *hint.Hints, vOrder, hOrder []
 	return nil, nil
 }
 
-func check(ctx context.Context, b *board.Board, h *hint.Hints, vOrder, hOrder []score, stats *stats) (*board.Board, error) {
-	stats.Count++
-
-	done, err := h.Check(b)
+// settle propagates, and probes when probing is set, b in place and reports
+// whether it is still consistent with the hints and whether it is completely
+// solved.
+func (s *search) settle(ctx context.Context, b *board.Board, probing bool) (valid, done bool, err error) {
+	done, err = s.h.Check(b)
 	if errors.As(err, &hint.ErrInvalid{}) {
-		return nil, nil
+		return false, false, nil
 	}
 	if err != nil {
-		return nil, err
+		return false, false, err
 	}
 	if done {
-		return b, nil
+		return true, true, nil
+	}
+	if !propagate(b, s.h, s.record) {
+		return false, false, nil
+	}
+	if probing {
+		valid, fixed, err := probe(ctx, b, s.h, s.record)
+		s.counters.probed.Add(uint64(fixed))
+		if err != nil {
+			return false, false, err
+		}
+		if !valid {
+			return false, false, nil
+		}
+	}
+	done, err = s.h.Check(b)
+	if errors.As(err, &hint.ErrInvalid{}) {
+		return false, false, nil
 	}
-	solved, err := solve(ctx, b, h, vOrder, hOrder, stats)
 	if err != nil {
-		return nil, err
+		return false, false, err
 	}
-	if solved != nil {
-		return solved, nil
+	return true, done, nil
+}
+
+// accept passes a solution to found and stops the proven board from moving,
+// since the other branches are no longer known to be dead ends.
+func (s *search) accept(b *board.Board) bool {
+	s.mutex.Lock()
+	s.solved = true
+	s.mutex.Unlock()
+	return s.found(b)
+}
+
+// prove moves the proven board to next, the only branch of from left to
+// explore, when from is the proven board and no solution was found yet.
+func (s *search) prove(from, next *board.Board) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.solved || s.proven != from {
+		return
+	}
+	s.proven = next
+}
+
+// branch returns a search of the subtree under b. It shares everything with s
+// but the proven board, which starts at b.
+func (s *search) branch(b *board.Board) *search {
+	return &search{
+		h:        s.h,
+		vOrder:   s.vOrder,
+		hOrder:   s.hOrder,
+		stats:    s.stats,
+		counters: s.counters,
+		found:    s.found,
+		record:   s.record,
+		proven:   b,
+	}
+}
+
+// intersect returns the cells known with the same state in all boards.
+func intersect(boards []*board.Board) *board.Board {
+	width, height := boards[0].Size()
+	b := board.New(width, height)
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			state := boards[0].Get(x, y)
+			for _, other := range boards[1:] {
+				if other.Get(x, y) != state {
+					state = board.Empty
+					break
+				}
+			}
+			b.Set(x, y, state)
+		}
+	}
+	return b
+}
+
+func (s *search) step(step Step) {
+	if s.record != nil {
+		s.record(step)
 	}
-	return nil, nil
 }
 
 func scoreSorter(a, b score) int {
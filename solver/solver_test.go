@@ -0,0 +1,350 @@
+package solver
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"nonogram/board"
+	"nonogram/hint"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func parse(rows ...string) *board.Board {
+	b := board.New(len([]rune(rows[0])), len(rows))
+	for y, row := range rows {
+		for x, r := range []rune(row) {
+			switch r {
+			case '#':
+				b.Set(x, y, board.Filled)
+			case 'X':
+				b.Set(x, y, board.Crossed)
+			}
+		}
+	}
+	return b
+}
+
+func requireSolution(t *testing.T, expected, actual *board.Board) {
+	width, height := expected.Size()
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			require.Equal(t, expected.Get(x, y) == board.Filled, actual.Get(x, y) == board.Filled, "cell %d,%d", x, y)
+		}
+	}
+}
+
+func TestSolve(t *testing.T) {
+	expected := parse(
+		"..##..##..",
+		".########.",
+		"##########",
+		"#########.",
+		"##########",
+		".########.",
+		"..######..",
+		"...####...",
+		"#...##....",
+		"#........#",
+	)
+	solved, _, err := Solve(context.Background(), board.New(10, 10), hint.FromBoard(expected))
+	require.NoError(t, err)
+	require.NotNil(t, solved)
+	requireSolution(t, expected, solved)
+}
+
+func TestSolveAll(t *testing.T) {
+	unique := parse(
+		"##.",
+		".##",
+		"#.#",
+	)
+	solutions, _, err := SolveAll(context.Background(), board.New(3, 3), hint.FromBoard(unique), 0)
+	require.NoError(t, err)
+	require.Len(t, solutions, 1)
+	requireSolution(t, unique, solutions[0])
+
+	ambiguous := parse(
+		"#..",
+		".#.",
+		"..#",
+	)
+	solutions, _, err = SolveAll(context.Background(), board.New(3, 3), hint.FromBoard(ambiguous), 0)
+	require.NoError(t, err)
+	require.Len(t, solutions, 6)
+
+	solutions, _, err = SolveAll(context.Background(), board.New(3, 3), hint.FromBoard(ambiguous), 2)
+	require.NoError(t, err)
+	require.Len(t, solutions, 2)
+
+	impossible := hint.New([][]int{{2}, {0}}, [][]int{{0}, {0}})
+	solutions, _, err = SolveAll(context.Background(), board.New(2, 2), impossible, 0)
+	require.NoError(t, err)
+	require.Empty(t, solutions)
+}
+
+func TestSolveParallel(t *testing.T) {
+	expected := parse(
+		"..##..##..",
+		".########.",
+		"##########",
+		"#########.",
+		"##########",
+		".########.",
+		"..######..",
+		"...####...",
+		"#...##....",
+		"#........#",
+	)
+	solutions, _, err := SolveParallel(context.Background(), board.New(10, 10), hint.FromBoard(expected), 2)
+	require.NoError(t, err)
+	require.Len(t, solutions, 1)
+	requireSolution(t, expected, solutions[0])
+
+	ambiguous := parse(
+		"#...",
+		".#..",
+		"..#.",
+		"...#",
+	)
+	solutions, _, err = SolveParallel(context.Background(), board.New(4, 4), hint.FromBoard(ambiguous), 0)
+	require.NoError(t, err)
+	require.Len(t, solutions, 24)
+
+	solutions, _, err = SolveParallel(context.Background(), board.New(4, 4), hint.FromBoard(ambiguous), 5)
+	require.NoError(t, err)
+	require.Len(t, solutions, 5)
+}
+
+func TestSolveParallelProven(t *testing.T) {
+	h := hint.FromBoard(parse(
+		"XXX#X#XX",
+		"XX#XXXX#",
+		"XX#XX###",
+		"#XXXXXX#",
+		"X#X#X#XX",
+	))
+	stats := Stats{}
+	s := newSearch(h, &stats, func(*board.Board) bool { return false })
+	s.proven = board.New(8, 5)
+	branches, leaves, err := s.split(context.Background(), s.proven, 4)
+	require.NoError(t, err)
+	require.NotEmpty(t, branches)
+	root := s.proven.Count(board.Empty)
+
+	// Time out right after the split, before any branch is searched.
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	s.explore(ctx, branches, leaves, 2)
+	require.Less(t, stats.Proven.Count(board.Empty), root)
+
+	solutions, _, err := SolveAll(context.Background(), board.New(8, 5), h, 0)
+	require.NoError(t, err)
+	for _, solution := range solutions {
+		for y := 0; y < 5; y++ {
+			for x := 0; x < 8; x++ {
+				if state := stats.Proven.Get(x, y); state != board.Empty {
+					require.Equal(t, state, solution.Get(x, y), "cell %d,%d", x, y)
+				}
+			}
+		}
+	}
+}
+
+func TestExplain(t *testing.T) {
+	expected := parse(
+		"..##..##..",
+		".########.",
+		"##########",
+		"#########.",
+		"##########",
+		".########.",
+		"..######..",
+		"...####...",
+		"#...##....",
+		"#........#",
+	)
+	solved, steps, _, err := Explain(context.Background(), board.New(10, 10), hint.FromBoard(expected))
+	require.NoError(t, err)
+	requireSolution(t, expected, solved)
+
+	replayed := board.New(10, 10)
+	for _, step := range steps {
+		require.Equal(t, StepDeduce, step.Kind)
+		require.Equal(t, RuleLine, step.Rule)
+		require.Equal(t, 0, step.Depth)
+		for _, cell := range step.Cells {
+			require.Equal(t, board.Empty, replayed.Get(cell.X, cell.Y))
+			if cell.Filled {
+				replayed.Set(cell.X, cell.Y, board.Filled)
+			} else {
+				replayed.Set(cell.X, cell.Y, board.Crossed)
+			}
+		}
+	}
+	requireSolution(t, expected, replayed)
+
+	_, steps, _, err = Explain(context.Background(), board.New(3, 3), hint.FromBoard(parse("#..", ".#.", "..#")))
+	require.NoError(t, err)
+	require.Equal(t, StepGuess, steps[0].Kind)
+	require.Equal(t, 1, steps[0].Depth)
+}
+
+func TestNext(t *testing.T) {
+	h := hint.FromBoard(parse(
+		"###",
+		"#..",
+		"#..",
+	))
+	b := board.New(3, 3)
+
+	step, err := Next(b, h)
+	require.NoError(t, err)
+	require.Equal(t, Step{Kind: StepDeduce, Rule: RuleLine, Direction: Row, Index: 0, Cells: []Cell{{X: 0, Y: 0, Filled: true}, {X: 1, Y: 0, Filled: true}, {X: 2, Y: 0, Filled: true}}}, step)
+	require.Equal(t, board.Empty, b.Get(0, 0))
+
+	_, err = Next(parse("###", "#XX", "#XX"), h)
+	require.ErrorIs(t, err, ErrNoDeduction{})
+
+	_, err = Next(parse("X..", "...", "..."), h)
+	require.ErrorAs(t, err, &hint.ErrInvalid{})
+}
+
+func TestSolveCancelled(t *testing.T) {
+	h := hint.FromBoard(parse(
+		"###",
+		"#..",
+		".#.",
+		"..#",
+	))
+	cause := errors.New("stop")
+	ctx, cancel := context.WithCancelCause(context.Background())
+	cancel(cause)
+
+	proven, stats, err := Solve(ctx, board.New(3, 4), h)
+	require.ErrorIs(t, err, cause)
+	require.Equal(t, parse(
+		"###",
+		"#XX",
+		"X..",
+		"X..",
+	), proven)
+	require.Equal(t, proven, stats.Proven)
+}
+
+func TestSolvers(t *testing.T) {
+	unique := parse(
+		"##.",
+		".##",
+		"#.#",
+	)
+	ambiguous := parse(
+		"#..",
+		".#.",
+		"..#",
+	)
+	for _, name := range []string{"backtrack", "parallel", "line", "probe"} {
+		s, err := Lookup(name)
+		require.NoError(t, err)
+
+		result, err := s.Solve(context.Background(), board.New(3, 3), hint.FromBoard(unique))
+		require.NoError(t, err)
+		require.Equal(t, Solved, result.Status, name)
+		requireSolution(t, unique, result.Board)
+
+		result, err = s.Solve(context.Background(), board.New(3, 3), hint.FromBoard(ambiguous))
+		require.NoError(t, err)
+		if name == "line" || name == "probe" {
+			require.Equal(t, Partial, result.Status, name)
+		} else {
+			require.Equal(t, Ambiguous, result.Status, name)
+		}
+	}
+
+	_, err := Lookup("missing")
+	require.ErrorAs(t, err, &ErrUnknownSolver{})
+}
+
+func TestProbing(t *testing.T) {
+	expected := parse(
+		"...#..",
+		"##.##.",
+		"..#...",
+		"#..###",
+		".....#",
+		".##.##",
+	)
+	result, err := Line{}.Solve(context.Background(), board.New(6, 6), hint.FromBoard(expected))
+	require.NoError(t, err)
+	require.Equal(t, Partial, result.Status)
+
+	result, err = Probing{}.Solve(context.Background(), board.New(6, 6), hint.FromBoard(expected))
+	require.NoError(t, err)
+	require.Equal(t, Solved, result.Status)
+	require.NotZero(t, result.Stats.Probed)
+	requireSolution(t, expected, result.Board)
+
+	cause := errors.New("stop")
+	ctx, cancel := context.WithCancelCause(context.Background())
+	cancel(cause)
+	result, err = Probing{}.Solve(ctx, board.New(6, 6), hint.FromBoard(expected))
+	require.ErrorIs(t, err, cause)
+	require.Equal(t, Partial, result.Status)
+}
+
+func TestRate(t *testing.T) {
+	expected := parse(
+		"#####",
+		"#...#",
+		"#####",
+	)
+	d, err := Rate(context.Background(), board.New(5, 3), hint.FromBoard(expected))
+	require.NoError(t, err)
+	require.Equal(t, Difficulty{Score: 100, Techniques: []Rule{RuleOverlap}}, d)
+
+	probing := parse(
+		"...#..",
+		"##.##.",
+		"..#...",
+		"#..###",
+		".....#",
+		".##.##",
+	)
+	d, err = Rate(context.Background(), board.New(6, 6), hint.FromBoard(probing))
+	require.NoError(t, err)
+	require.Contains(t, d.Techniques, RuleProbe)
+	require.NotContains(t, d.Techniques, RuleGuess)
+	require.Equal(t, 300+d.Probed, d.Score)
+
+	ambiguous := hint.New([][]int{{1}, {1}}, [][]int{{1}, {1}})
+	d, err = Rate(context.Background(), board.New(2, 2), ambiguous)
+	require.NoError(t, err)
+	require.Equal(t, []Rule{RuleGuess}, d.Techniques)
+	require.Equal(t, 1, d.Depth)
+
+	_, err = Rate(context.Background(), board.New(2, 2), hint.New([][]int{{2}, {0}}, [][]int{{0}, {0}}))
+	require.ErrorAs(t, err, &ErrNoSolution{})
+}
+
+func TestResultJSON(t *testing.T) {
+	expected := parse(
+		"#.#",
+		".##",
+	)
+	result, err := Line{}.Solve(context.Background(), board.New(3, 2), hint.FromBoard(expected))
+	require.NoError(t, err)
+	data, err := json.Marshal(result)
+	require.NoError(t, err)
+
+	decoded := map[string]any{}
+	require.NoError(t, json.Unmarshal(data, &decoded))
+	require.Equal(t, []any{"#X#", "X##"}, decoded["board"])
+	require.Equal(t, "solved", decoded["status"])
+	require.Equal(t, float64(1), decoded["stats"].(map[string]any)["count"])
+	require.NotZero(t, decoded["took"])
+
+	h := &hint.Hints{}
+	require.NoError(t, json.Unmarshal([]byte(`{"columns": [[1], [1], [2]], "rows": [[1, 1], [2]]}`), h))
+	require.Equal(t, hint.FromBoard(expected), h)
+}
@@ -0,0 +1,165 @@
+package solver
+
+import (
+	"context"
+	"nonogram/board"
+	"nonogram/hint"
+	"slices"
+	"sync"
+	"time"
+)
+
+type Status string
+
+const (
+	// Solved means Board is the only solution of the puzzle.
+	Solved Status = "solved"
+	// Ambiguous means Board is one of several solutions of the puzzle.
+	Ambiguous Status = "ambiguous"
+	// NoSolution means the hints contradict each other or the known cells.
+	NoSolution Status = "no_solution"
+	// Partial means the solver stopped before finding a solution, either
+	// because it was cancelled or because its technique is not strong
+	// enough. Board holds the cells proven so far.
+	Partial Status = "partial"
+)
+
+// Result is the outcome of a Solver. In JSON the board is the string grid of
+// board.Board, null when there is no solution, and took is in nanoseconds:
+//
+//	{
+//	  "board": ["#.X", "..#"],
+//	  "status": "solved",
+//	  "stats": {"start": "2024-01-02T15:04:05Z", "count": 12, "probed": 0},
+//	  "took": 1500000
+//	}
+type Result struct {
+	Board  *board.Board  `json:"board"`
+	Status Status        `json:"status"`
+	Stats  Stats         `json:"stats"`
+	Took   time.Duration `json:"took"`
+}
+
+// Solver solves a puzzle with one strategy. When ctx is cancelled it returns
+// a Partial result together with the cause.
+type Solver interface {
+	Solve(ctx context.Context, b *board.Board, h *hint.Hints) (Result, error)
+}
+
+const DefaultSolver = "parallel"
+
+var (
+	registry      = map[string]Solver{}
+	registryMutex sync.RWMutex
+)
+
+func init() {
+	Register("backtrack", Backtracking{})
+	Register("parallel", Parallel{})
+	Register("line", Line{})
+	Register("probe", Probing{})
+}
+
+// Register makes a solver available by name. It panics when the name is
+// already taken.
+func Register(name string, s Solver) {
+	registryMutex.Lock()
+	defer registryMutex.Unlock()
+	if _, ok := registry[name]; ok {
+		panic("solver: Register called twice for " + name)
+	}
+	registry[name] = s
+}
+
+func Lookup(name string) (Solver, error) {
+	registryMutex.RLock()
+	defer registryMutex.RUnlock()
+	s, ok := registry[name]
+	if !ok {
+		return nil, ErrUnknownSolver{name: name}
+	}
+	return s, nil
+}
+
+func Names() []string {
+	registryMutex.RLock()
+	defer registryMutex.RUnlock()
+	names := make([]string, 0, len(registry))
+	for name := range registry {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// Backtracking is the single threaded search of SolveAll.
+type Backtracking struct {
+}
+
+func (t Backtracking) Solve(ctx context.Context, b *board.Board, h *hint.Hints) (Result, error) {
+	return newResult(SolveAll(ctx, b, h, 2))
+}
+
+// Parallel is the search of SolveParallel.
+type Parallel struct {
+}
+
+func (t Parallel) Solve(ctx context.Context, b *board.Board, h *hint.Hints) (Result, error) {
+	return newResult(SolveParallel(ctx, b, h, 2))
+}
+
+// Line only propagates line logic and never guesses, so it returns a Partial
+// result for puzzles that are not line solvable.
+type Line struct {
+}
+
+func (t Line) Solve(ctx context.Context, b *board.Board, h *hint.Hints) (Result, error) {
+	return deduce(ctx, b, h, false)
+}
+
+// Probing propagates line logic and probes cells but never guesses, which is
+// how a person solves most published puzzles. It returns a Partial result
+// when that is not enough.
+type Probing struct {
+}
+
+func (t Probing) Solve(ctx context.Context, b *board.Board, h *hint.Hints) (Result, error) {
+	return deduce(ctx, b, h, true)
+}
+
+func deduce(ctx context.Context, b *board.Board, h *hint.Hints, probing bool) (Result, error) {
+	stats := Stats{Start: time.Now()}
+	s := newSearch(h, &stats, nil)
+	s.counters.count.Add(1)
+	stats.Proven = b.Clone()
+	valid, done, err := s.settle(ctx, stats.Proven, probing)
+	s.collect()
+	took := time.Since(stats.Start)
+	if err != nil && context.Cause(ctx) != nil {
+		return Result{Board: stats.Proven, Status: Partial, Stats: stats, Took: took}, err
+	}
+	if err != nil {
+		return Result{Stats: stats, Took: took}, err
+	}
+	if !valid {
+		return Result{Status: NoSolution, Stats: stats, Took: took}, nil
+	}
+	if !done {
+		return Result{Board: stats.Proven, Status: Partial, Stats: stats, Took: took}, nil
+	}
+	return Result{Board: stats.Proven, Status: Solved, Stats: stats, Took: took}, nil
+}
+
+func newResult(solutions []*board.Board, stats Stats, err error) (Result, error) {
+	took := time.Since(stats.Start)
+	switch {
+	case err != nil:
+		return Result{Board: stats.Proven, Status: Partial, Stats: stats, Took: took}, err
+	case len(solutions) == 0:
+		return Result{Status: NoSolution, Stats: stats, Took: took}, nil
+	case len(solutions) == 1:
+		return Result{Board: solutions[0], Status: Solved, Stats: stats, Took: took}, nil
+	default:
+		return Result{Board: solutions[0], Status: Ambiguous, Stats: stats, Took: took}, nil
+	}
+}